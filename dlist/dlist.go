@@ -5,16 +5,69 @@ package dlist
 import (
 	"../layer"
 	"../mymath"
+	"math"
 )
 
 ////////////////////////
 //public structure/types
 ////////////////////////
 
+//path segment kinds
+const (
+	Line_segment = iota
+	Cubic_segment
+	Arc_segment
+)
+
+//a retained path segment, it starts where the one before it ends and its points are
+//absolute. a line has its end point, a cubic its two control points and end point,
+//an arc its center and, for svg arcs, its exact end point. Dist is the flattening
+//tolerance the segment was added with
+type Segment struct {
+	Kind     int
+	Points   mymath.Points
+	Rx       float32
+	Ry       float32
+	Rotation float32
+	Start    float32
+	Sweep    float32
+	Dist     float32
+}
+
 /////////////////////////
 //private structure/types
 /////////////////////////
 
+//a path as a start point and segments, flattened on demand and cached per tolerance.
+//points64 holds its points in float64 while it is built only with Add_path64
+type retained_path struct {
+	start    *mymath.Point
+	segments []*Segment
+	cache    map[float32]*mymath.Points
+	points64 []mymath.Vec64
+}
+
+//how a strip was made, so it can be rebuilt when its paths change
+type strip_source struct {
+	path_ids []int
+	radii    []float32
+	build    func(source *strip_source) *mymath.Points
+}
+
+//a live collision path entry, so it can be moved when its path changes. radii
+//follow the path's points as it is edited, added_radii are the ones it was added
+//with, which is what a Sub matches it by. entries are held in float64 whichever
+//api added them
+type collision struct {
+	transform   *mymath.Matrix64
+	path_id     int
+	radius      float64
+	radii       []float32
+	added_radii []float32
+	gap         float64
+	id          int
+}
+
 //////////////
 //dlist object
 //////////////
@@ -24,8 +77,10 @@ type Dlist struct {
 	width         int
 	height        int
 	scale         int
-	paths         map[int]*mymath.Points
+	paths         map[int]*retained_path
 	strips        map[int]*mymath.Points
+	strip_sources map[int]*strip_source
+	collisions    map[int][]*collision
 	next_path_id  int
 	next_strip_id int
 }
@@ -34,14 +89,54 @@ type Dlist struct {
 //public methods
 ////////////////
 
-func Newdlist(width, height, scale int) *Dlist {
+//options configure the collision layer, see layer.Float64_coords, layer.Sparse_buckets,
+//layer.Quadtree_index and layer.Rtree_index
+func Newdlist(width, height, scale int, opts ...layer.Option) *Dlist {
 	d := Dlist{}
-	d.init(width, height, scale)
+	d.init(width, height, scale, opts...)
 	return &d
 }
 
+//flattened path, curves flattened to the tolerance they were added with
 func (self *Dlist) Get_path(id int) *mymath.Points {
-	return self.paths[id]
+	return self.Get_path_at(id, 0.0)
+}
+
+//flattened path with every curve flattened to dist, a dist of 0 uses the
+//tolerance each curve was added with
+func (self *Dlist) Get_path_at(id int, dist float32) *mymath.Points {
+	p, ok := self.paths[id]
+	if !ok {
+		return nil
+	}
+	return p.flatten(dist)
+}
+
+//copies of a path's start point and segments, nil if the path is empty or unknown
+func (self *Dlist) Get_segments(id int) (*mymath.Point, []*Segment) {
+	p, ok := self.paths[id]
+	if !ok || (p.start == nil) {
+		return nil, nil
+	}
+	segments := make([]*Segment, len(p.segments), len(p.segments))
+	for i, seg := range p.segments {
+		c := *seg
+		c.Points = make(mymath.Points, len(seg.Points), len(seg.Points))
+		for j, pp := range seg.Points {
+			c.Points[j] = &mymath.Point{(*pp)[0], (*pp)[1]}
+		}
+		segments[i] = &c
+	}
+	return &mymath.Point{(*p.start)[0], (*p.start)[1]}, segments
+}
+
+//replace a path's segments, rebuilding anything made from it.
+//pass new segments, not ones edited in place
+func (self *Dlist) Set_segments(id int, start *mymath.Point, segments []*Segment) {
+	self.update_path(id, func(p *retained_path) {
+		p.start = start
+		p.segments = segments
+	})
 }
 
 func (self *Dlist) Get_strip(id int) *mymath.Points {
@@ -50,109 +145,539 @@ func (self *Dlist) Get_strip(id int) *mymath.Points {
 
 func (self *Dlist) Create_path() int {
 	self.next_path_id++
-	self.paths[self.next_path_id] = &mymath.Points{}
+	self.paths[self.next_path_id] = &retained_path{cache: map[float32]*mymath.Points{}}
 	return self.next_path_id
 }
 
+//collision entries on the path are taken out of the layer and forgotten, strips
+//made from it are kept as they are but no longer follow it
 func (self *Dlist) Delete_path(id int) {
+	if _, ok := self.paths[id]; !ok {
+		return
+	}
+	for cid, entries := range self.collisions {
+		kept := entries[:0]
+		for _, c := range entries {
+			if c.path_id == id {
+				self.layer_sub(c)
+			} else {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			delete(self.collisions, cid)
+		} else {
+			self.collisions[cid] = kept
+		}
+	}
+	for strip_id, source := range self.strip_sources {
+		for _, path_id := range source.path_ids {
+			if path_id == id {
+				delete(self.strip_sources, strip_id)
+				break
+			}
+		}
+	}
 	delete(self.paths, id)
 }
 
 func (self *Dlist) Add_rel_path(id int, points *mymath.Points) {
-	path := *self.paths[id]
-	rx := float32(0.0)
-	ry := float32(0.0)
-	ex := float32(0.0)
-	ey := float32(0.0)
-	if len(path) != 0 {
-		epp := path[len(path)-1]
-		ep := *epp
-		ex = ep[0]
-		ey = ep[1]
-	} else {
-		path = append(path, &mymath.Point{0.0, 0.0})
+	self.extend_path(id, func(p *retained_path) {
+		rx := float32(0.0)
+		ry := float32(0.0)
+		if p.start == nil {
+			p.start = &mymath.Point{0.0, 0.0}
+		}
+		ep := *p.end()
+		ex := ep[0]
+		ey := ep[1]
+		for _, pp := range *points {
+			p1 := *pp
+			rx += p1[0]
+			ry += p1[1]
+			p1[0] = rx + ex
+			p1[1] = ry + ey
+			p.segments = append(p.segments, &Segment{Kind: Line_segment, Points: mymath.Points{pp}})
+		}
+	})
+}
+
+func (self *Dlist) Add_abs_path(id int, pointsp *mymath.Points) {
+	points := *pointsp
+	if len(points) == 0 {
+		return
 	}
-	for _, pp := range *points {
-		p := *pp
-		rx += p[0]
-		ry += p[1]
-		p[0] = rx + ex
-		p[1] = ry + ey
-		path = append(path, pp)
+	self.extend_path(id, func(p *retained_path) {
+		ex := float32(0.0)
+		ey := float32(0.0)
+		start := 0
+		if p.start != nil {
+			ep := *p.end()
+			ex = ep[0]
+			ey = ep[1]
+			start = 1
+		}
+		for i := start; i < len(points); i++ {
+			pp := points[i]
+			p1 := *pp
+			p1[0] += ex
+			p1[1] += ey
+			if p.start == nil {
+				p.start = pp
+			} else {
+				p.segments = append(p.segments, &Segment{Kind: Line_segment, Points: mymath.Points{pp}})
+			}
+		}
+	})
+}
+
+//append absolute float64 points to a path. a path built only this way keeps its
+//points in float64 for its collision entries, see Get_path64. any other edit, or
+//adding to a path that already has float32 points, leaves it float32 only
+func (self *Dlist) Add_path64(id int, points []mymath.Vec64) {
+	if len(points) == 0 {
+		return
 	}
-	self.paths[id] = &path
+	self.edit_path(id, true, func(p *retained_path) {
+		keep := (p.start == nil) || (p.points64 != nil)
+		for _, v := range points {
+			pp := &mymath.Point{float32(v.X), float32(v.Y)}
+			if p.start == nil {
+				p.start = pp
+			} else {
+				p.segments = append(p.segments, &Segment{Kind: Line_segment, Points: mymath.Points{pp}})
+			}
+		}
+		if keep {
+			p.points64 = append(p.points64, points...)
+		} else {
+			p.points64 = nil
+		}
+	})
 }
 
-func (self *Dlist) Add_abs_path(id int, pointsp *mymath.Points) {
-	path := *self.paths[id]
-	ex := float32(0.0)
-	ey := float32(0.0)
-	start := 0
-	if len(path) != 0 {
-		epp := path[len(path)-1]
-		ep := *epp
-		ex = ep[0]
-		ey = ep[1]
-		start = 1
+//append absolute integer points in the layer's units, as Add_path64. they are held
+//in float64, which gives them back exactly for any coordinate under 2^50 units
+func (self *Dlist) Add_path_i64(id int, points []mymath.Ipoint) {
+	units := self.layer.Units()
+	path := make([]mymath.Vec64, len(points), len(points))
+	for i, p := range points {
+		path[i] = mymath.Vec64{float64(p.X) / units, float64(p.Y) / units}
 	}
-	points := *pointsp
-	for i := start; i < len(points); i++ {
-		pp := points[i]
-		p := *pp
-		p[0] += ex
-		p[1] += ey
-		path = append(path, pp)
+	self.Add_path64(id, path)
+}
+
+//float64 points of a path, as added for a path built with Add_path64, otherwise
+//its flattened points. a stored path is shared so must not be changed
+func (self *Dlist) Get_path64(id int) []mymath.Vec64 {
+	p, ok := self.paths[id]
+	if !ok {
+		return nil
+	}
+	if p.points64 != nil {
+		return p.points64
 	}
-	self.paths[id] = &path
+	points := *p.flatten(0.0)
+	path := make([]mymath.Vec64, len(points), len(points))
+	for i, pp := range points {
+		v := *pp
+		path[i] = mymath.Vec64{float64(v[0]), float64(v[1])}
+	}
+	return path
 }
 
 func (self *Dlist) Add_bezier(id int, p2, p3, p4 *mymath.Point, dist float32) {
-	path := *self.paths[id]
-	ex := float32(0.0)
-	ey := float32(0.0)
-	start := 0
-	if len(path) != 0 {
-		epp := path[len(path)-1]
-		ep := *epp
-		ex = ep[0]
-		ey = ep[1]
-		start = 1
-	}
-	points := *mymath.Bezier_path_as_lines(&mymath.Point{0.0, 0.0}, p2, p3, p4, dist)
-	for i := start; i < len(points); i++ {
-		pp := points[i]
-		p := *pp
-		p[0] += ex
-		p[1] += ey
-		path = append(path, pp)
+	self.add_beziers(id, &mymath.Points{&mymath.Point{0.0, 0.0}, p2, p3, p4}, dist)
+}
+
+//quadratics are kept as the equivalent cubic
+func (self *Dlist) Add_quadratic(id int, p2, p3 *mymath.Point, dist float32) {
+	c1 := mymath.Scale_2d(p2, 2.0/3.0)
+	c2 := mymath.Add_2d(p3, mymath.Scale_2d(mymath.Sub_2d(p2, p3), 2.0/3.0))
+	self.add_beziers(id, &mymath.Points{&mymath.Point{0.0, 0.0}, c1, c2, p3}, dist)
+}
+
+func (self *Dlist) Add_arc(id int, center *mymath.Point, rx, ry, rotation, start, sweep, dist float32) {
+	self.extend_path(id, func(p *retained_path) {
+		if p.start == nil {
+			p.start = &mymath.Point{0.0, 0.0}
+		}
+		//center is relative to the path end, join the end to the arc start with a line
+		c := mymath.Add_2d(p.end(), center)
+		arc_start := arc_point(c, rx, ry, rotation, start)
+		if !mymath.Equal_2d(arc_start, p.end()) {
+			p.segments = append(p.segments, &Segment{Kind: Line_segment, Points: mymath.Points{arc_start}})
+		}
+		p.segments = append(p.segments, &Segment{Arc_segment, mymath.Points{c}, rx, ry, rotation, start, sweep, dist})
+	})
+}
+
+func (self *Dlist) Add_svg_arc(id int, p2 *mymath.Point, rx, ry, rotation float32, large_arc, sweep bool, dist float32) {
+	self.extend_path(id, func(p *retained_path) {
+		if p.start == nil {
+			p.start = &mymath.Point{0.0, 0.0}
+		}
+		origin := &mymath.Point{0.0, 0.0}
+		end := mymath.Add_2d(p.end(), p2)
+		if mymath.Equal_2d(p2, origin) {
+			return
+		}
+		if (rx == 0.0) || (ry == 0.0) {
+			p.segments = append(p.segments, &Segment{Kind: Line_segment, Points: mymath.Points{end}})
+			return
+		}
+		center, frx, fry, theta, delta := mymath.Svg_arc_center(origin, p2, rx, ry, rotation, large_arc, sweep)
+		c := mymath.Add_2d(p.end(), center)
+		p.segments = append(p.segments, &Segment{Arc_segment, mymath.Points{c, end}, frx, fry, rotation, theta, delta, dist})
+	})
+}
+
+//smooth curve through the points, points chain relative like Add_rel_path
+func (self *Dlist) Add_catmull_rom(id int, points *mymath.Points, dist float32) {
+	self.add_beziers(id, mymath.Catmull_rom_as_beziers(spline_controls(points)), dist)
+}
+
+//smooth curve guided by the points, points chain relative like Add_rel_path
+func (self *Dlist) Add_bspline(id int, points *mymath.Points, dist float32) {
+	self.add_beziers(id, mymath.Bspline_as_beziers(spline_controls(points)), dist)
+}
+
+//fit beziers to sampled points within tolerance and keep them as cubic segments,
+//flattened to dist and can be flattened again with Flatten_curve_path
+func (self *Dlist) Create_fitted_path(points *mymath.Points, tolerance, dist float32) int {
+	id := self.Create_path()
+	ctrl := *mymath.Fit_beziers(points, tolerance)
+	if len(ctrl) == 0 {
+		return id
+	}
+	p := self.paths[id]
+	p.start = ctrl[0]
+	for i := 1; i+2 < len(ctrl); i += 3 {
+		p.segments = append(p.segments, &Segment{Kind: Cubic_segment, Points: mymath.Points{ctrl[i], ctrl[i+1], ctrl[i+2]}, Dist: dist})
 	}
-	self.paths[id] = &path
+	return id
+}
+
+//bezier control points of a path made only of cubics, 3n+1 of them, else nil
+func (self *Dlist) Get_curve(id int) *mymath.Points {
+	p, ok := self.paths[id]
+	if !ok || (p.start == nil) || (len(p.segments) == 0) {
+		return nil
+	}
+	ctrl := mymath.Points{p.start}
+	for _, seg := range p.segments {
+		if seg.Kind != Cubic_segment {
+			return nil
+		}
+		ctrl = append(ctrl, seg.Points...)
+	}
+	return &ctrl
+}
+
+//flatten every curve of a path again at a new tolerance, rebuilding anything made from it
+func (self *Dlist) Flatten_curve_path(id int, dist float32) {
+	self.update_path(id, func(p *retained_path) {
+		for _, seg := range p.segments {
+			seg.Dist = dist
+		}
+	})
+}
+
+func (self *Dlist) Union_paths(id1, id2, fill_rule int) []int {
+	return self.clip_paths(id1, id2, 0, fill_rule)
+}
+
+func (self *Dlist) Intersect_paths(id1, id2, fill_rule int) []int {
+	return self.clip_paths(id1, id2, 1, fill_rule)
+}
+
+func (self *Dlist) Difference_paths(id1, id2, fill_rule int) []int {
+	return self.clip_paths(id1, id2, 2, fill_rule)
+}
+
+func (self *Dlist) Xor_paths(id1, id2, fill_rule int) []int {
+	return self.clip_paths(id1, id2, 3, fill_rule)
+}
+
+//offset a closed path, each result contour becomes a new path, see mymath.Offset_polygons
+func (self *Dlist) Offset_path(id int, distance float32, joinstyle, resolution int, mitre_limit float32) []int {
+	ids := []int{}
+	for _, contour := range mymath.Offset_polygons([]*mymath.Points{self.Get_path(id)}, distance, joinstyle, resolution, mitre_limit) {
+		ids = append(ids, self.new_path(contour))
+	}
+	return ids
+}
+
+//outline round an open path, each result contour becomes a new path, see mymath.Offset_polyline
+func (self *Dlist) Offset_open_path(id int, distance float32, capstyle, joinstyle, resolution int, mitre_limit float32) []int {
+	ids := []int{}
+	for _, contour := range mymath.Offset_polyline(self.Get_path(id), distance, capstyle, joinstyle, resolution, mitre_limit) {
+		ids = append(ids, self.new_path(contour))
+	}
+	return ids
 }
 
 func (self *Dlist) Create_path_strip(id int, radius float32, capstyle, joinstyle, resolution int) int {
-	self.next_strip_id++
-	points := mymath.Thicken_path_as_tristrip(self.paths[id], radius, capstyle, joinstyle, resolution)
-	self.strips[self.next_strip_id] = points
-	return self.next_strip_id
+	return self.Create_path_strip_ex(id, radius, capstyle, joinstyle, resolution, mymath.No_mitre_limit)
+}
+
+//mitre joins longer than mitre_limit times the radius are bevelled
+func (self *Dlist) Create_path_strip_ex(id int, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		return mymath.Thicken_path_as_tristrip_ex(self.Get_path(id), radius, capstyle, joinstyle, resolution, mitre_limit)
+	}, nil, id)
+}
+
+//stroke a closed path with no caps, joining its last point back to the first
+func (self *Dlist) Create_closed_path_strip(id int, radius float32, joinstyle, resolution int, mitre_limit float32) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		return mymath.Thicken_closed_path_as_tristrip(self.Get_path(id), radius, joinstyle, resolution, mitre_limit)
+	}, nil, id)
+}
+
+func (self *Dlist) Create_dashed_path_strip(id int, pattern []float32, phase, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		strips := []*mymath.Points{}
+		for _, dash := range mymath.Dash_path(self.Get_path(id), pattern, phase) {
+			strips = append(strips, mymath.Thicken_path_as_tristrip_ex(dash, radius, capstyle, joinstyle, resolution, mitre_limit))
+		}
+		return mymath.Join_tristrips(strips)
+	}, nil, id)
+}
+
+func (self *Dlist) Create_variable_path_strip(id int, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		return mymath.Thicken_variable_path_as_tristrip(self.Get_path(id), source.radii, capstyle, joinstyle, resolution, mitre_limit)
+	}, radii, id)
+}
+
+func (self *Dlist) Create_variable_closed_path_strip(id int, radii []float32, joinstyle, resolution int, mitre_limit float32) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		return mymath.Thicken_variable_closed_path_as_tristrip(self.Get_path(id), source.radii, joinstyle, resolution, mitre_limit)
+	}, radii, id)
+}
+
+func (self *Dlist) Get_path_radii(id int, width func(s, length float32) float32) []float32 {
+	return mymath.Path_radii(self.Get_path(id), width)
 }
 
 func (self *Dlist) Create_circle_strip(center *mymath.Point, radius1, radius2 float32, resolution int) int {
-	self.next_strip_id++
-	points := mymath.Circle_as_tristrip(center, radius1, radius2, resolution)
-	self.strips[self.next_strip_id] = points
-	return self.next_strip_id
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		return mymath.Circle_as_tristrip(center, radius1, radius2, resolution)
+	}, nil)
+}
+
+func (self *Dlist) Create_fill_strip(id int, hole_ids ...int) int {
+	return self.add_strip(func(source *strip_source) *mymath.Points {
+		holes := make([]*mymath.Points, len(hole_ids), len(hole_ids))
+		for i, hole_id := range hole_ids {
+			holes[i] = self.Get_path(hole_id)
+		}
+		return mymath.Triangles_as_tristrip(mymath.Triangulate_polygon(self.Get_path(id), holes))
+	}, nil, append([]int{id}, hole_ids...)...)
 }
 
 func (self *Dlist) Delete_strip(id int) {
 	delete(self.strips, id)
+	delete(self.strip_sources, id)
+}
+
+//simplify a path in place, method 0 is ramer douglas peucker with tolerance the
+//largest distance error, method 1 is visvalingam whyatt with tolerance the smallest
+//area kept. strips and collision entries using the path are rebuilt
+func (self *Dlist) Simplify_path(id int, tolerance float32, method int) {
+	var points *mymath.Points
+	if method == 0 {
+		points = mymath.Simplify_path_rdp(self.Get_path(id), tolerance)
+	} else {
+		points = mymath.Simplify_path_vw(self.Get_path(id), tolerance)
+	}
+	self.update_path(id, func(p *retained_path) {
+		p.set_points(points)
+	})
+}
+
+func (self *Dlist) Get_path_length(id int) float32 {
+	return mymath.Path_length(self.Get_path(id))
+}
+
+func (self *Dlist) Get_path_point(id int, distance float32) *mymath.Point {
+	return mymath.Path_point_at(self.Get_path(id), distance)
+}
+
+func (self *Dlist) Get_path_tangent(id int, distance float32) *mymath.Point {
+	return mymath.Path_tangent_at(self.Get_path(id), distance)
+}
+
+func (self *Dlist) Get_path_normal(id int, distance float32) *mymath.Point {
+	return mymath.Path_normal_at(self.Get_path(id), distance)
+}
+
+//area, winding, perimeter and convexity do not depend on where an instance is placed
+func (self *Dlist) Get_path_area(id int) float32 {
+	return mymath.Polygon_area(self.Get_path(id))
+}
+
+func (self *Dlist) Get_path_winding(id int) int {
+	return mymath.Polygon_winding(self.Get_path(id))
+}
+
+func (self *Dlist) Get_path_perimeter(id int) float32 {
+	return mymath.Polygon_perimeter(self.Get_path(id))
+}
+
+func (self *Dlist) Is_path_convex(id int) bool {
+	return mymath.Polygon_is_convex(self.Get_path(id))
+}
+
+func (self *Dlist) Get_path_centroid(offset *mymath.Point, id int) *mymath.Point {
+	return mymath.Add_2d(mymath.Polygon_centroid(self.Get_path(id)), offset)
+}
+
+func (self *Dlist) Get_path_bounds(offset *mymath.Point, id int) (*mymath.Point, *mymath.Point) {
+	min, max := mymath.Path_bounds(self.Get_path(id))
+	return mymath.Add_2d(min, offset), mymath.Add_2d(max, offset)
+}
+
+//test a point against an instance of a closed path, fill_rule as for mymath.Clip_polygons
+func (self *Dlist) Hit_path(offset *mymath.Point, id int, p *mymath.Point, fill_rule int) bool {
+	return mymath.Point_in_polygon(mymath.Sub_2d(p, offset), self.Get_path(id), fill_rule)
+}
+
+func (self *Dlist) Get_path_hull(offset *mymath.Point, id int) *mymath.Points {
+	return mymath.Convex_hull(self.Get_transformed_path(id, offset_matrix(offset)))
+}
+
+func (self *Dlist) Get_path_min_rect(offset *mymath.Point, id int) *mymath.Points {
+	return mymath.Min_area_rect(self.Get_transformed_path(id, offset_matrix(offset)))
+}
+
+func (self *Dlist) Get_path_min_circle(offset *mymath.Point, id int) (*mymath.Point, float32) {
+	return mymath.Min_enclosing_circle(self.Get_transformed_path(id, offset_matrix(offset)))
+}
+
+//split a path a distance along it into two new paths, the original is left alone
+func (self *Dlist) Split_path(id int, distance float32) (int, int) {
+	first, second := mymath.Split_path_at(self.Get_path(id), distance)
+	return self.new_path(first), self.new_path(second)
 }
 
 func (self *Dlist) Add_collision_path(offset *mymath.Point, path_id int, radius, gap float32, id int) {
-	self.layer.Add_path(offset, self.paths[path_id], radius, gap, id)
+	self.Add_transformed_collision_path(offset_matrix(offset), path_id, radius, gap, id)
 }
 
 func (self *Dlist) Sub_collision_path(offset *mymath.Point, path_id int, radius, gap float32, id int) {
-	self.layer.Sub_path(offset, self.paths[path_id], radius, gap, id)
+	self.Sub_transformed_collision_path(offset_matrix(offset), path_id, radius, gap, id)
+}
+
+func (self *Dlist) Add_variable_collision_path(offset *mymath.Point, path_id int, radii []float32, gap float32, id int) {
+	self.Add_transformed_variable_collision_path(offset_matrix(offset), path_id, radii, gap, id)
+}
+
+func (self *Dlist) Sub_variable_collision_path(offset *mymath.Point, path_id int, radii []float32, gap float32, id int) {
+	self.Sub_transformed_variable_collision_path(offset_matrix(offset), path_id, radii, gap, id)
+}
+
+//radii scale with the transform, the gap does not, see layer.Layer.Add_transformed_path
+func (self *Dlist) Add_transformed_collision_path(transform *mymath.Matrix, path_id int, radius, gap float32, id int) {
+	self.add_collision(&collision{mymath.Matrix_to_matrix64(transform), path_id, float64(radius), nil, nil, float64(gap), id})
+}
+
+func (self *Dlist) Sub_transformed_collision_path(transform *mymath.Matrix, path_id int, radius, gap float32, id int) {
+	self.sub_collision(&collision{mymath.Matrix_to_matrix64(transform), path_id, float64(radius), nil, nil, float64(gap), id})
+}
+
+//radii are fitted to the path's points as mymath.Fit_radii does
+func (self *Dlist) Add_transformed_variable_collision_path(transform *mymath.Matrix, path_id int, radii []float32, gap float32, id int) {
+	added := append([]float32{}, radii...)
+	fitted := mymath.Fit_radii(added, len(self.Get_path64(path_id)))
+	self.add_collision(&collision{mymath.Matrix_to_matrix64(transform), path_id, 0.0, fitted, added, float64(gap), id})
+}
+
+func (self *Dlist) Sub_transformed_variable_collision_path(transform *mymath.Matrix, path_id int, radii []float32, gap float32, id int) {
+	self.sub_collision(&collision{mymath.Matrix_to_matrix64(transform), path_id, 0.0, radii, radii, float64(gap), id})
+}
+
+//float64 placement, for float64 and integer layers, see layer.Float64_coords.
+//paths built with Add_path64 keep their float64 points
+func (self *Dlist) Add_transformed_collision_path64(transform *mymath.Matrix64, path_id int, radius, gap float64, id int) {
+	self.add_collision(&collision{transform, path_id, radius, nil, nil, gap, id})
+}
+
+func (self *Dlist) Sub_transformed_collision_path64(transform *mymath.Matrix64, path_id int, radius, gap float64, id int) {
+	self.sub_collision(&collision{transform, path_id, radius, nil, nil, gap, id})
+}
+
+//integer placement in the layer's units, see layer.Int64_coords
+func (self *Dlist) Add_collision_path_i64(offset mymath.Ipoint, path_id int, radius, gap int64, id int) {
+	self.add_collision(self.collision_i64(offset, path_id, radius, gap, id))
+}
+
+func (self *Dlist) Sub_collision_path_i64(offset mymath.Ipoint, path_id int, radius, gap int64, id int) {
+	self.sub_collision(self.collision_i64(offset, path_id, radius, gap, id))
+}
+
+//path outline with a transform applied, for drawing instances
+func (self *Dlist) Get_transformed_path(id int, transform *mymath.Matrix) *mymath.Points {
+	return mymath.Transform_points(transform, self.Get_path(id))
+}
+
+//strip with a transform applied, for drawing instances
+func (self *Dlist) Get_transformed_strip(id int, transform *mymath.Matrix) *mymath.Points {
+	return mymath.Transform_points(transform, self.strips[id])
+}
+
+//collision ids touching the rectangle with corners p1 and p2, or only those wholly inside it
+func (self *Dlist) Hit_collision_rect(p1, p2 *mymath.Point, contained bool) []int {
+	return self.layer.Hit_Rect(p1, p2, contained)
+}
+
+//collision ids touching the polygon, or only those wholly inside it
+func (self *Dlist) Hit_collision_polygon(pathp *mymath.Points, contained bool) []int {
+	return self.layer.Hit_Polygon(pathp, contained)
+}
+
+//the collision id nearest a point and the distance to its surface, nil if there are none
+func (self *Dlist) Nearest_collision_path(offsetp *mymath.Point) *layer.Nearest {
+	return self.layer.Nearest_Line(segment_line(offsetp, offsetp))
+}
+
+//the k collision ids nearest a point, nearest first
+func (self *Dlist) Nearest_collision_paths(offsetp *mymath.Point, k int) []*layer.Nearest {
+	return self.layer.Nearest_Lines(segment_line(offsetp, offsetp), k)
+}
+
+//the collision id nearest the segment p1 to p2, nil if there are none
+func (self *Dlist) Nearest_collision_path_to_segment(p1, p2 *mymath.Point) *layer.Nearest {
+	return self.layer.Nearest_Line(segment_line(p1, p2))
+}
+
+//the k collision ids nearest the segment p1 to p2, nearest first
+func (self *Dlist) Nearest_collision_paths_to_segment(p1, p2 *mymath.Point, k int) []*layer.Nearest {
+	return self.layer.Nearest_Lines(segment_line(p1, p2), k)
+}
+
+//bounding shapes of every collision path added under the ids, radii included
+func (self *Dlist) Get_collision_hull(ids []int) *mymath.Points {
+	return mymath.Convex_hull(self.collision_points(ids))
+}
+
+func (self *Dlist) Get_collision_min_rect(ids []int) *mymath.Points {
+	return mymath.Min_area_rect(self.collision_points(ids))
+}
+
+func (self *Dlist) Get_collision_min_circle(ids []int) (*mymath.Point, float32) {
+	return mymath.Min_enclosing_circle(self.collision_points(ids))
+}
+
+//collision id under a float64 point, as Hit_collision_path
+func (self *Dlist) Hit_collision_path64(p mymath.Vec64) int {
+	return self.layer.Hit_Line64(&layer.Line64{p, p, 0.01, 0.0, 0.0})
+}
+
+//collision id exactly at an integer point in the layer's units
+func (self *Dlist) Hit_collision_path_i64(p mymath.Ipoint) int {
+	return self.layer.Hit_Line_i64(&layer.Line_i64{p, p, 0, 0, 0})
 }
 
 func (self *Dlist) Hit_collision_path(offsetp *mymath.Point) int {
@@ -160,25 +685,448 @@ func (self *Dlist) Hit_collision_path(offsetp *mymath.Point) int {
 	x := offset[0]
 	y := offset[1]
 	l := layer.Point{x, y}
-	line := layer.Line{&l, &l, 0.01, 0.0}
+	line := layer.Line{&l, &l, 0.01, 0.0, 0.0}
 	return self.layer.Hit_Line(&line)
 }
 
+//every collision id under the point, nearest first when sorted
+func (self *Dlist) Hit_collision_paths(offsetp *mymath.Point, sorted bool) []int {
+	return self.layer.Hit_Line_all(point_line(offsetp), sorted)
+}
+
+//call f with every collision id under the point until f returns false
+func (self *Dlist) Hit_collision_paths_each(offsetp *mymath.Point, f func(id int) bool) {
+	self.layer.Hit_Line_each(point_line(offsetp), f)
+}
+
 /////////////////
 //private methods
 /////////////////
 
-func (self *Dlist) init(width, height, scale int) {
+func (self *Dlist) init(width, height, scale int, opts ...layer.Option) {
 	self.paths = nil
 	self.width = width
 	self.height = height
 	self.scale = scale
-	self.paths = map[int]*mymath.Points{}
+	self.paths = map[int]*retained_path{}
 	self.strips = map[int]*mymath.Points{}
+	self.strip_sources = map[int]*strip_source{}
+	self.collisions = map[int][]*collision{}
 	self.next_path_id = -1
 	self.next_strip_id = -1
 	cols := width / scale
 	rows := height / scale
-	self.layer = layer.Newlayer(cols+1, rows+1, 1.0/(float32(width)/float32(cols)), 1.0/(float32(height)/float32(rows)))
+	self.layer = layer.Newlayer(cols+1, rows+1, 1.0/(float32(width)/float32(cols)), 1.0/(float32(height)/float32(rows)), opts...)
 	return
 }
+
+//each result contour becomes a new path, holes wind clockwise
+func (self *Dlist) clip_paths(id1, id2, op, fill_rule int) []int {
+	ids := []int{}
+	subject := []*mymath.Points{self.Get_path(id1)}
+	clip := []*mymath.Points{self.Get_path(id2)}
+	for _, contour := range mymath.Clip_polygons(subject, clip, op, fill_rule) {
+		ids = append(ids, self.new_path(contour))
+	}
+	return ids
+}
+
+//per point radii are fitted to the first path's points
+func (self *Dlist) add_strip(build func(source *strip_source) *mymath.Points, radii []float32, path_ids ...int) int {
+	if radii != nil {
+		radii = mymath.Fit_radii(radii, len(self.Get_path64(path_ids[0])))
+	}
+	self.next_strip_id++
+	source := &strip_source{path_ids, radii, build}
+	self.strip_sources[self.next_strip_id] = source
+	self.strips[self.next_strip_id] = build(source)
+	return self.next_strip_id
+}
+
+//forget the first live collision entry matching c and take its lines out of
+//the layer, as it now stands. nothing is taken out without a match
+func (self *Dlist) sub_collision(c *collision) {
+	entries := self.collisions[c.id]
+	for i, e := range entries {
+		if (e.path_id != c.path_id) || (e.radius != c.radius) || (e.gap != c.gap) {
+			continue
+		}
+		if (*e.transform != *c.transform) || !radii_equal(e.added_radii, c.added_radii) {
+			continue
+		}
+		if len(entries) == 1 {
+			delete(self.collisions, c.id)
+		} else {
+			self.collisions[c.id] = append(entries[:i:i], entries[i+1:]...)
+		}
+		self.layer_sub(e)
+		return
+	}
+}
+
+func (self *Dlist) add_collision(c *collision) {
+	self.collisions[c.id] = append(self.collisions[c.id], c)
+	self.layer_add(c)
+}
+
+//integer placement as a float64 one in coordinate units, the layer rounds it back
+func (self *Dlist) collision_i64(offset mymath.Ipoint, path_id int, radius, gap int64, id int) *collision {
+	units := self.layer.Units()
+	transform := mymath.Translate_matrix64(float64(offset.X)/units, float64(offset.Y)/units)
+	return &collision{transform, path_id, float64(radius) / units, nil, nil, float64(gap) / units, id}
+}
+
+func (self *Dlist) layer_add(c *collision) {
+	self.layer_add_from(c, 0)
+}
+
+//add the lines of a collision entry from the path's point first onwards
+func (self *Dlist) layer_add_from(c *collision, first int) {
+	path := self.Get_path64(c.path_id)[first:]
+	if c.radii != nil {
+		self.layer.Add_transformed_variable_path64(c.transform, path, radii64(c.radii[first:]), c.gap, c.id)
+	} else {
+		self.layer.Add_transformed_path64(c.transform, path, c.radius, c.gap, c.id)
+	}
+}
+
+func (self *Dlist) layer_sub(c *collision) {
+	if c.radii != nil {
+		self.layer.Sub_transformed_variable_path64(c.transform, self.Get_path64(c.path_id), radii64(c.radii), c.gap, c.id)
+	} else {
+		self.layer.Sub_transformed_path64(c.transform, self.Get_path64(c.path_id), c.radius, c.gap, c.id)
+	}
+}
+
+//the points of the collision paths under the ids, each grown to a polygon that
+//encloses its radius so shapes built from them enclose the thick lines
+func (self *Dlist) collision_points(ids []int) *mymath.Points {
+	const sides = 16
+	grow := 1.0 / math.Cos(math.Pi/sides)
+	points := mymath.Points{}
+	for _, id := range ids {
+		for _, c := range self.collisions[id] {
+			path := self.Get_path64(c.path_id)
+			for i, v := range path {
+				tv := mymath.Transform_vec64(c.transform, v)
+				p := &mymath.Point{float32(tv.X), float32(tv.Y)}
+				radius := c.radius
+				if c.radii != nil {
+					radius = float64(c.radii[i])
+				}
+				if radius == 0.0 {
+					points = append(points, p)
+					continue
+				}
+				//the widest of the lines either side of the point
+				scale := 0.0
+				if i > 0 {
+					scale = mymath.Matrix64_line_scale(c.transform, path[i-1], v)
+				}
+				if i+1 < len(path) {
+					scale = math.Max(scale, mymath.Matrix64_line_scale(c.transform, v, path[i+1]))
+				}
+				if len(path) == 1 {
+					scale = mymath.Matrix64_scale(c.transform)
+				}
+				points = append(points, *mymath.Circle_as_lines(p, float32(radius*scale*grow), sides)...)
+			}
+		}
+	}
+	return &points
+}
+
+//new path holding a line segment per point
+func (self *Dlist) new_path(points *mymath.Points) int {
+	id := self.Create_path()
+	self.paths[id].set_points(points)
+	return id
+}
+
+//append cubics given as 3n+1 control points relative to the path end
+func (self *Dlist) add_beziers(id int, ctrlp *mymath.Points, dist float32) {
+	ctrl := *ctrlp
+	if len(ctrl) == 0 {
+		return
+	}
+	self.extend_path(id, func(p *retained_path) {
+		if p.start == nil {
+			p.start = &mymath.Point{(*ctrl[0])[0], (*ctrl[0])[1]}
+		}
+		e := p.end()
+		for i := 1; i+2 < len(ctrl); i += 3 {
+			c1, c2, p4 := mymath.Add_2d(e, ctrl[i]), mymath.Add_2d(e, ctrl[i+1]), mymath.Add_2d(e, ctrl[i+2])
+			p.segments = append(p.segments, &Segment{Kind: Cubic_segment, Points: mymath.Points{c1, c2, p4}, Dist: dist})
+		}
+	})
+}
+
+//change a path, moving collision entries and rebuilding strips that use it.
+//per point radii follow the points they belong to
+func (self *Dlist) update_path(id int, change func(p *retained_path)) {
+	self.edit_path(id, false, func(p *retained_path) {
+		p.points64 = nil
+		change(p)
+	})
+}
+
+//add segments to the end of a path, its cached flattenings are extended
+//rather than made again, a float64 path drops to float32 so is remade
+func (self *Dlist) extend_path(id int, change func(p *retained_path)) {
+	self.edit_path(id, self.paths[id].points64 == nil, func(p *retained_path) {
+		p.points64 = nil
+		change(p)
+	})
+}
+
+func (self *Dlist) edit_path(id int, extend bool, change func(p *retained_path)) {
+	p := self.paths[id]
+	collisions := []*collision{}
+	for _, entries := range self.collisions {
+		for _, c := range entries {
+			if c.path_id == id {
+				collisions = append(collisions, c)
+			}
+		}
+	}
+	strip_ids := []int{}
+	for strip_id, source := range self.strip_sources {
+		for _, path_id := range source.path_ids {
+			if path_id == id {
+				strip_ids = append(strip_ids, strip_id)
+				break
+			}
+		}
+	}
+	used := (len(collisions) != 0) || (len(strip_ids) != 0)
+	var old *mymath.Points
+	if used {
+		old = p.flatten(0.0)
+	}
+	//extending keeps the old lines in the layer, only the new ones are added
+	extend = extend && (p.start != nil)
+	if !extend {
+		for _, c := range collisions {
+			self.layer_sub(c)
+		}
+	}
+	count := len(p.segments)
+	change(p)
+	if extend {
+		p.extend(count)
+	} else {
+		p.cache = map[float32]*mymath.Points{}
+	}
+	if !used {
+		return
+	}
+	points := p.flatten(0.0)
+	for _, c := range collisions {
+		c.radii = remap_radii(old, points, c.radii)
+		if extend {
+			self.layer_add_from(c, len(*old)-1)
+		} else {
+			self.layer_add(c)
+		}
+	}
+	for _, strip_id := range strip_ids {
+		source := self.strip_sources[strip_id]
+		source.radii = remap_radii(old, points, source.radii)
+		self.strips[strip_id] = source.build(source)
+	}
+}
+
+///////////////////////
+//retained path methods
+///////////////////////
+
+//end point of the path, nil if it is empty
+func (self *retained_path) end() *mymath.Point {
+	if len(self.segments) == 0 {
+		return self.start
+	}
+	return segment_end(self.segments[len(self.segments)-1])
+}
+
+//replace the segments with a line segment per point
+func (self *retained_path) set_points(pointsp *mymath.Points) {
+	points := *pointsp
+	self.start = nil
+	self.segments = nil
+	if len(points) == 0 {
+		return
+	}
+	self.start = points[0]
+	for _, pp := range points[1:] {
+		self.segments = append(self.segments, &Segment{Kind: Line_segment, Points: mymath.Points{pp}})
+	}
+}
+
+//flatten to dist, or each segment's own tolerance for 0, line end points are shared
+func (self *retained_path) flatten(dist float32) *mymath.Points {
+	if points, ok := self.cache[dist]; ok {
+		return points
+	}
+	points := mymath.Points{}
+	if self.start != nil {
+		points = append(points, self.start)
+	}
+	points = flatten_segments(points, self.segments, dist)
+	self.cache[dist] = &points
+	return &points
+}
+
+//flatten the segments from the first new one onto the end of each cached
+//flattening, the cached points themselves are left as they were
+func (self *retained_path) extend(first int) {
+	for dist, cached := range self.cache {
+		points := flatten_segments(*cached, self.segments[first:], dist)
+		self.cache[dist] = &points
+	}
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func offset_matrix(offsetp *mymath.Point) *mymath.Matrix {
+	offset := *offsetp
+	return mymath.Translate_matrix(offset[0], offset[1])
+}
+
+//tiny query line at a point, as Hit_collision_path uses
+func point_line(offsetp *mymath.Point) *layer.Line {
+	offset := *offsetp
+	l := layer.Point{offset[0], offset[1]}
+	return &layer.Line{&l, &l, 0.01, 0.0, 0.0}
+}
+
+func segment_line(pp1, pp2 *mymath.Point) *layer.Line {
+	p1, p2 := *pp1, *pp2
+	return &layer.Line{&layer.Point{p1[0], p1[1]}, &layer.Point{p2[0], p2[1]}, 0.0, 0.0, 0.0}
+}
+
+//append the flattened segments to points, which end where the first segment starts
+func flatten_segments(points mymath.Points, segments []*Segment, dist float32) mymath.Points {
+	for _, seg := range segments {
+		d := dist
+		if d == 0.0 {
+			d = seg.Dist
+		}
+		switch seg.Kind {
+		case Cubic_segment:
+			curve := *mymath.Bezier_path_as_lines(points[len(points)-1], seg.Points[0], seg.Points[1], seg.Points[2], d)
+			points = append(points, curve[1:len(curve)-1]...)
+			points = append(points, seg.Points[2])
+		case Arc_segment:
+			curve := *mymath.Arc_as_lines(seg.Points[0], seg.Rx, seg.Ry, seg.Rotation, seg.Start, seg.Sweep, d)
+			if len(seg.Points) > 1 {
+				curve[len(curve)-1] = seg.Points[1]
+			}
+			points = append(points, curve[1:]...)
+		default:
+			points = append(points, seg.Points[0])
+		}
+	}
+	return points
+}
+
+func segment_end(seg *Segment) *mymath.Point {
+	switch seg.Kind {
+	case Cubic_segment:
+		return seg.Points[2]
+	case Arc_segment:
+		if len(seg.Points) > 1 {
+			return seg.Points[1]
+		}
+		return arc_point(seg.Points[0], seg.Rx, seg.Ry, seg.Rotation, seg.Start+seg.Sweep)
+	default:
+		return seg.Points[0]
+	}
+}
+
+//point on a rotated ellipse, as Arc_as_lines computes it
+func arc_point(center *mymath.Point, rx, ry, rotation, angle float32) *mymath.Point {
+	c := *center
+	sr := math.Sin(float64(rotation))
+	cr := math.Cos(float64(rotation))
+	x := math.Abs(float64(rx)) * math.Cos(float64(angle))
+	y := math.Abs(float64(ry)) * math.Sin(float64(angle))
+	return &mymath.Point{c[0] + float32(x*cr-y*sr), c[1] + float32(x*sr+y*cr)}
+}
+
+//relative spline points to points from the path end, which is the first of them
+func spline_controls(pointsp *mymath.Points) *mymath.Points {
+	rx := float32(0.0)
+	ry := float32(0.0)
+	points := mymath.Points{&mymath.Point{0.0, 0.0}}
+	for _, pp := range *pointsp {
+		p := *pp
+		rx += p[0]
+		ry += p[1]
+		points = append(points, &mymath.Point{rx, ry})
+	}
+	return &points
+}
+
+func radii64(radii []float32) []float64 {
+	out := make([]float64, len(radii), len(radii))
+	for i, r := range radii {
+		out[i] = float64(r)
+	}
+	return out
+}
+
+func radii_equal(r1, r2 []float32) bool {
+	if len(r1) != len(r2) {
+		return false
+	}
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//carry per point radii over to a new set of points. each new point takes the radius
+//where it lies along the old path, found by walking forward along the old path as
+//the new one goes, so kept points keep their radius, points between old ones blend
+//and points past the old end take the last radius
+func remap_radii(oldp, newp *mymath.Points, radii []float32) []float32 {
+	if radii == nil {
+		return nil
+	}
+	old, points := *oldp, *newp
+	if len(old) < 2 {
+		return mymath.Fit_radii(radii, len(points))
+	}
+	radii = mymath.Fit_radii(radii, len(old))
+	out := make([]float32, len(points), len(points))
+	j, last := 0, float32(0.0)
+	for i, p := range points {
+		//the new point is near where the last one was, a little further on
+		reach := last
+		if i != 0 {
+			reach += mymath.Distance_2d(points[i-1], p)
+		}
+		best, bt, bj := float32(math.MaxFloat32), float32(0.0), j
+		for k, along := j, float32(0.0); k+1 < len(old); k++ {
+			if (k != j) && (along > best+reach*4.0) {
+				break
+			}
+			lv := mymath.Sub_2d(old[k+1], old[k])
+			t := float32(0.0)
+			if l := mymath.Dot_2d(lv, lv); l != 0.0 {
+				t = float32(math.Max(0.0, math.Min(1.0, float64(mymath.Dot_2d(mymath.Sub_2d(p, old[k]), lv)/l))))
+			}
+			if d := mymath.Distance_2d(p, mymath.Add_2d(old[k], mymath.Scale_2d(lv, t))); d < best {
+				best, bt, bj = d, t, k
+			}
+			along += mymath.Length_2d(lv)
+		}
+		j, last = bj, best
+		out[i] = radii[j] + (radii[j+1]-radii[j])*bt
+	}
+	return out
+}
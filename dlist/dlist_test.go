@@ -0,0 +1,207 @@
+//package name
+package dlist
+
+//package imports
+import (
+	"../mymath"
+	"math"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+func test_dlist() *Dlist {
+	return Newdlist(1024, 768, 8)
+}
+
+//a path through the points, given absolute
+func test_path(d *Dlist, points ...*mymath.Point) int {
+	id := d.Create_path()
+	path := mymath.Points(points)
+	d.Add_abs_path(id, &path)
+	return id
+}
+
+///////
+//tests
+///////
+
+//a sub that matches no live entry leaves the layer alone, even when its
+//radii do not fit the path
+func TestSub_collision_unmatched(t *testing.T) {
+	d := test_dlist()
+	origin := &mymath.Point{0.0, 0.0}
+	id := test_path(d, &mymath.Point{100.0, 100.0}, &mymath.Point{200.0, 100.0}, &mymath.Point{200.0, 200.0})
+	d.Add_variable_collision_path(origin, id, []float32{2.0, 2.0, 2.0}, 0.0, 1)
+	d.Add_collision_path(origin, id, 1.0, 0.0, 2)
+	d.Sub_variable_collision_path(origin, id, []float32{2.0}, 0.0, 1)
+	d.Sub_collision_path(origin, id, 3.0, 0.0, 2)
+	d.Sub_collision_path(&mymath.Point{1.0, 0.0}, id, 1.0, 0.0, 2)
+	hits := d.Hit_collision_paths(&mymath.Point{150.0, 100.0}, false)
+	if len(hits) != 2 {
+		t.Fatalf("unmatched subs left hits %v, want both entries", hits)
+	}
+	d.Sub_variable_collision_path(origin, id, []float32{2.0, 2.0, 2.0}, 0.0, 1)
+	d.Sub_collision_path(origin, id, 1.0, 0.0, 2)
+	if hit := d.Hit_collision_path(&mymath.Point{150.0, 100.0}); hit != -1 {
+		t.Fatalf("matched subs left hit %d", hit)
+	}
+}
+
+//radii of the wrong length are fitted to the path, for strips and collisions
+func TestVariable_radii_fitted(t *testing.T) {
+	d := test_dlist()
+	origin := &mymath.Point{0.0, 0.0}
+	id := test_path(d, &mymath.Point{100.0, 100.0}, &mymath.Point{200.0, 100.0}, &mymath.Point{200.0, 200.0})
+	d.Create_variable_path_strip(id, []float32{2.0}, 0, 0, 16, mymath.Default_mitre_limit)
+	d.Add_variable_collision_path(origin, id, []float32{2.0}, 0.0, 1)
+	if hit := d.Hit_collision_path(&mymath.Point{201.5, 150.0}); hit != 1 {
+		t.Fatalf("hit %d beside the second line, want 1", hit)
+	}
+	d.Add_rel_path(id, &mymath.Points{&mymath.Point{-100.0, 0.0}})
+	if hit := d.Hit_collision_path(&mymath.Point{150.0, 201.5}); hit != 1 {
+		t.Fatalf("hit %d beside the appended line, want 1", hit)
+	}
+	d.Sub_variable_collision_path(origin, id, []float32{2.0}, 0.0, 1)
+	if hit := d.Hit_collision_path(&mymath.Point{200.0, 150.0}); hit != -1 {
+		t.Fatalf("hit %d after the sub", hit)
+	}
+}
+
+//a fitted path keeps its curves, flattening them finer gives more points on the same curve
+func TestCreate_fitted_path(t *testing.T) {
+	d := test_dlist()
+	samples := mymath.Points{}
+	for i := 0; i <= 100; i++ {
+		x := float32(i) * 4.0
+		samples = append(samples, &mymath.Point{100.0 + x, 300.0 + 50.0*float32(math.Sin(float64(x)*0.02))})
+	}
+	id := d.Create_fitted_path(&samples, 0.5, 2.0)
+	ctrl := d.Get_curve(id)
+	if (ctrl == nil) || ((len(*ctrl)-1)%3 != 0) {
+		t.Fatalf("curve %v, want 3n+1 control points", ctrl)
+	}
+	coarse := len(*d.Get_path(id))
+	d.Flatten_curve_path(id, 0.05)
+	fine := d.Get_path(id)
+	if len(*fine) <= coarse {
+		t.Errorf("%d points flattened finer, %d before", len(*fine), coarse)
+	}
+	if !mymath.Equal_2d((*fine)[0], samples[0]) || !mymath.Equal_2d((*fine)[len(*fine)-1], samples[len(samples)-1]) {
+		t.Errorf("ends %v %v, want the sample ends", (*fine)[0], (*fine)[len(*fine)-1])
+	}
+	if d.Get_curve(id) == nil {
+		t.Error("curve lost by flattening")
+	}
+}
+
+//under scale, mirror and rotation a collision path keeps the stroke the transformed
+//strip has, so points just inside and outside it in path space stay so
+func TestTransformed_collision_path(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform *mymath.Matrix
+	}{
+		{"identity", mymath.Identity_matrix()},
+		{"half scale", mymath.Scale_matrix(0.5, 0.5)},
+		{"non uniform scale", mymath.Scale_matrix(2.0, 0.5)},
+		{"mirror", mymath.Scale_matrix(-1.0, 1.0)},
+		{"rotate", mymath.Rotate_matrix(0.5)},
+	}
+	probes := []struct {
+		p   *mymath.Point
+		hit bool
+	}{
+		{&mymath.Point{50.0, 3.5}, true},
+		{&mymath.Point{50.0, -3.5}, true},
+		{&mymath.Point{50.0, 4.5}, false},
+		{&mymath.Point{50.0, -4.5}, false},
+		{&mymath.Point{103.5, 50.0}, true},
+		{&mymath.Point{96.5, 50.0}, true},
+		{&mymath.Point{104.5, 50.0}, false},
+		{&mymath.Point{95.5, 50.0}, false},
+	}
+	for _, test := range tests {
+		d := test_dlist()
+		id := test_path(d, &mymath.Point{0.0, 0.0}, &mymath.Point{100.0, 0.0}, &mymath.Point{100.0, 100.0})
+		transform := mymath.Mul_matrix(mymath.Translate_matrix(500.0, 400.0), test.transform)
+		d.Add_transformed_collision_path(transform, id, 4.0, 0.0, 1)
+		for _, probe := range probes {
+			hit := d.Hit_collision_path(mymath.Transform_point(transform, probe.p)) == 1
+			if hit != probe.hit {
+				t.Errorf("%s: hit %v at %v, want %v", test.name, hit, probe.p, probe.hit)
+			}
+		}
+		d.Sub_transformed_collision_path(transform, id, 4.0, 0.0, 1)
+		for _, probe := range probes {
+			if d.Hit_collision_path(mymath.Transform_point(transform, probe.p)) != -1 {
+				t.Errorf("%s: hit at %v after the sub", test.name, probe.p)
+			}
+		}
+	}
+}
+
+//a curve path with collision entries and a strip through flattening, extending,
+//simplifying and deleting. per point radii follow where their points lie on the path
+func TestEdit_path(t *testing.T) {
+	d := test_dlist()
+	id := test_path(d, &mymath.Point{100.0, 400.0})
+	d.Add_bezier(id, &mymath.Point{0.0, -100.0}, &mymath.Point{300.0, -100.0}, &mymath.Point{300.0, 0.0}, 20.0)
+	n := len(*d.Get_path(id))
+	radii := make([]float32, n, n)
+	for i := range radii {
+		radii[i] = 1.0 + 8.0*float32(i)/float32(n-1)
+	}
+	d.Add_variable_collision_path(&mymath.Point{0.0, 0.0}, id, radii, 0.0, 1)
+	d.Add_collision_path(&mymath.Point{0.0, 0.0}, id, 0.5, 0.0, 2)
+	strip_id := d.Create_variable_path_strip(id, radii, 0, 0, 16, mymath.Default_mitre_limit)
+	//the middle of the curve runs level through 250, 325 with a radius near 5
+	middle := func(what string) {
+		if hit := d.Hit_collision_path(&mymath.Point{250.0, 321.5}); hit != 1 {
+			t.Errorf("%s: hit %d inside the middle of the curve, want 1", what, hit)
+		}
+		if hit := d.Hit_collision_path(&mymath.Point{250.0, 319.0}); hit != -1 {
+			t.Errorf("%s: hit %d outside the middle of the curve", what, hit)
+		}
+	}
+	middle("added")
+	d.Flatten_curve_path(id, 0.5)
+	if len(*d.Get_path(id)) <= n {
+		t.Fatalf("%d points flattened finer, %d before", len(*d.Get_path(id)), n)
+	}
+	middle("flattened finer")
+	//extending keeps the cached points and the new line takes the last radius
+	before := *d.Get_path(id)
+	strip := len(*d.Get_strip(strip_id))
+	d.Add_rel_path(id, &mymath.Points{&mymath.Point{0.0, 100.0}})
+	after := *d.Get_path(id)
+	if (len(after) != len(before)+1) || (after[len(before)-1] != before[len(before)-1]) {
+		t.Errorf("extended to %d points from %d, the cache was remade", len(after), len(before))
+	}
+	if hit := d.Hit_collision_path(&mymath.Point{408.5, 450.0}); hit != 1 {
+		t.Errorf("hit %d beside the extension, want 1", hit)
+	}
+	if hits := d.Hit_collision_paths(&mymath.Point{400.0, 450.0}, true); (len(hits) != 2) || (hits[0]+hits[1] != 3) {
+		t.Errorf("hits %v on the extension, want both", hits)
+	}
+	if len(*d.Get_strip(strip_id)) <= strip {
+		t.Error("strip not rebuilt for the extension")
+	}
+	d.Simplify_path(id, 1.0, 0)
+	if len(*d.Get_path(id)) >= len(after) {
+		t.Errorf("simplified to %d points from %d", len(*d.Get_path(id)), len(after))
+	}
+	middle("simplified")
+	//deleting takes the entries out and leaves the strip
+	d.Delete_path(id)
+	for _, p := range []*mymath.Point{{250.0, 325.0}, {400.0, 450.0}, {100.0, 400.0}} {
+		if hit := d.Hit_collision_path(p); hit != -1 {
+			t.Errorf("hit %d at %v after the delete", hit, p)
+		}
+	}
+	if (len(d.collisions) != 0) || (len(d.strip_sources) != 0) || (d.Get_strip(strip_id) == nil) {
+		t.Errorf("%d collision ids and %d strip sources left, strip %v", len(d.collisions), len(d.strip_sources), d.Get_strip(strip_id) != nil)
+	}
+}
@@ -0,0 +1,260 @@
+//package name
+package layer
+
+//package imports
+import (
+	"math"
+	"sort"
+)
+
+/////////////////////////
+//private structure/types
+/////////////////////////
+
+type aabb struct {
+	minx int
+	miny int
+	maxx int
+	maxy int
+}
+
+type bucket []interface{}
+type buckets []bucket
+
+//uniform grid of buckets, each item goes in every bucket its box covers.
+//a sparse grid keeps its buckets in a map and has no edges
+type grid struct {
+	width   int
+	height  int
+	scalex  float64
+	scaley  float64
+	buckets buckets
+	sparse  map[[2]int]bucket
+	extent  *aabb
+	shrunk  bool
+	count   int
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func newgrid(width, height int, sx, sy float32, sparse bool) *grid {
+	g := grid{}
+	g.width = width
+	g.height = height
+	g.scalex = float64(sx)
+	g.scaley = float64(sy)
+	if sparse {
+		g.sparse = map[[2]int]bucket{}
+	} else {
+		g.buckets = make(buckets, (width * height), (width * height))
+		for i := 0; i < (width * height); i++ {
+			g.buckets[i] = bucket{}
+		}
+	}
+	return &g
+}
+
+/////////////////
+//Index interface
+/////////////////
+
+func (self *grid) Add(item interface{}, box *Box) {
+	if !box_finite(box) {
+		return
+	}
+	self.count++
+	if self.sparse != nil {
+		//sparse queries are clipped to the buckets in use
+		self.grow(self.bounds(box))
+	}
+	bb := self.aabb(box)
+	if self.sparse == nil {
+		self.grow(bb)
+	}
+	for y := bb.miny; y < bb.maxy; y++ {
+		for x := bb.minx; x < bb.maxx; x++ {
+			self.set_bucket(x, y, append(self.bucket(x, y), item))
+		}
+	}
+}
+
+func (self *grid) Sub(item interface{}, box *Box) {
+	if !box_finite(box) {
+		return
+	}
+	self.count--
+	self.shrunk = true
+	bb := self.aabb(box)
+	for y := bb.miny; y < bb.maxy; y++ {
+		for x := bb.minx; x < bb.maxx; x++ {
+			b := self.bucket(x, y)
+			for i := len(b) - 1; i >= 0; i-- {
+				if b[i] == item {
+					self.set_bucket(x, y, append(b[:i], b[i+1:]...))
+					break
+				}
+			}
+		}
+	}
+}
+
+func (self *grid) Hit(box *Box, hit func(item interface{}) bool) interface{} {
+	return index_hit(self, box, hit)
+}
+
+//a sparse grid walks its used buckets instead when there are fewer of them
+func (self *grid) Query(box *Box, f func(item interface{}) bool) {
+	if !box_finite(box) {
+		return
+	}
+	bb := self.aabb(box)
+	visit := func(b bucket) bool {
+		for _, item := range b {
+			if !f(item) {
+				return false
+			}
+		}
+		return true
+	}
+	if (self.sparse != nil) && ((bb.maxx-bb.minx)*(bb.maxy-bb.miny) > len(self.sparse)) {
+		keys := [][2]int{}
+		for key := range self.sparse {
+			if (key[0] >= bb.minx) && (key[0] < bb.maxx) && (key[1] >= bb.miny) && (key[1] < bb.maxy) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i][1] != keys[j][1] {
+				return keys[i][1] < keys[j][1]
+			}
+			return keys[i][0] < keys[j][0]
+		})
+		for _, key := range keys {
+			if !visit(self.sparse[key]) {
+				return
+			}
+		}
+		return
+	}
+	for y := bb.miny; y < bb.maxy; y++ {
+		for x := bb.minx; x < bb.maxx; x++ {
+			if !visit(self.bucket(x, y)) {
+				return
+			}
+		}
+	}
+}
+
+func (self *grid) Load(items []interface{}, boxes []*Box) {
+	for i, item := range items {
+		self.Add(item, boxes[i])
+	}
+}
+
+//the buckets in use, lines off the edge of a dense grid are in its edge buckets
+func (self *grid) Bounds() *Box {
+	if self.count == 0 {
+		return nil
+	}
+	if self.shrunk {
+		self.fit_extent()
+	}
+	bb := self.extent
+	if bb == nil {
+		return nil
+	}
+	return &Box{float64(bb.minx) / self.scalex, float64(bb.miny) / self.scaley,
+		float64(bb.maxx) / self.scalex, float64(bb.maxy) / self.scaley}
+}
+
+/////////////////
+//private methods
+/////////////////
+
+//grow the extent to take in the buckets
+func (self *grid) grow(bb *aabb) {
+	if (bb.minx >= bb.maxx) || (bb.miny >= bb.maxy) {
+		return
+	}
+	if self.extent == nil {
+		self.extent = bb
+	}
+	self.extent = &aabb{min_int(self.extent.minx, bb.minx), min_int(self.extent.miny, bb.miny),
+		max_int(self.extent.maxx, bb.maxx), max_int(self.extent.maxy, bb.maxy)}
+}
+
+//shrink the extent back to the buckets still in use after subs
+func (self *grid) fit_extent() {
+	self.extent = nil
+	self.shrunk = false
+	if self.sparse != nil {
+		for key := range self.sparse {
+			self.grow(&aabb{key[0], key[1], key[0] + 1, key[1] + 1})
+		}
+		return
+	}
+	for y := 0; y < self.height; y++ {
+		for x := 0; x < self.width; x++ {
+			if len(self.buckets[y*self.width+x]) != 0 {
+				self.grow(&aabb{x, y, x + 1, y + 1})
+			}
+		}
+	}
+}
+
+func (self *grid) bucket(x, y int) bucket {
+	if self.sparse != nil {
+		return self.sparse[[2]int{x, y}]
+	}
+	return self.buckets[y*self.width+x]
+}
+
+//empty sparse buckets are dropped
+func (self *grid) set_bucket(x, y int, b bucket) {
+	if self.sparse == nil {
+		self.buckets[y*self.width+x] = b
+	} else if len(b) == 0 {
+		delete(self.sparse, [2]int{x, y})
+	} else {
+		self.sparse[[2]int{x, y}] = b
+	}
+}
+
+func (self *grid) aabb(box *Box) *aabb {
+	bb := self.bounds(box)
+	return self.clip_aabb(bb.minx, bb.miny, bb.maxx, bb.maxy)
+}
+
+//buckets a box covers, not clipped to the grid
+func (self *grid) bounds(box *Box) *aabb {
+	minx := int(math.Floor(box.Minx * self.scalex))
+	miny := int(math.Floor(box.Miny * self.scaley))
+	maxx := int(math.Ceil(box.Maxx * self.scalex))
+	maxy := int(math.Ceil(box.Maxy * self.scaley))
+	return &aabb{minx, miny, maxx, maxy}
+}
+
+func (self *grid) clip_aabb(minx, miny, maxx, maxy int) *aabb {
+	if self.sparse != nil {
+		if self.extent == nil {
+			return &aabb{0, 0, 0, 0}
+		}
+		return &aabb{max_int(minx, self.extent.minx), max_int(miny, self.extent.miny),
+			min_int(maxx, self.extent.maxx), min_int(maxy, self.extent.maxy)}
+	}
+	if minx < 0 {
+		minx = 0
+	}
+	if miny < 0 {
+		miny = 0
+	}
+	if maxx > self.width {
+		maxx = self.width
+	}
+	if maxy > self.height {
+		maxy = self.height
+	}
+	return &aabb{minx, miny, maxx, maxy}
+}
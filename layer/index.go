@@ -0,0 +1,147 @@
+//package name
+package layer
+
+//package imports
+import (
+	"math"
+)
+
+////////////////////////
+//public structure/types
+////////////////////////
+
+//axis aligned box in layer coordinates
+type Box struct {
+	Minx float64
+	Miny float64
+	Maxx float64
+	Maxy float64
+}
+
+//a spatial index of opaque items by box, the layer keeps its lines in one. a query can
+//report an item more than once, and items that are only near the box, as the layer
+//tests every item it is given. a box that is not finite, from a line with a NaN or
+//infinite coordinate, is never indexed, Add, Sub and Load skip it and a query with
+//one finds nothing
+type Index interface {
+	//add an item that is not already in the index
+	Add(item interface{}, box *Box)
+	//remove an item, box is the one it was added with
+	Sub(item interface{}, box *Box)
+	//first item near the box that hit returns true for, nil if none
+	Hit(box *Box, hit func(item interface{}) bool) interface{}
+	//call f with the items near the box until f returns false
+	Query(box *Box, f func(item interface{}) bool)
+	//add many items at once, which some indexes build better than one at a time
+	Load(items []interface{}, boxes []*Box)
+	//box holding every item, nil if the index is empty
+	Bounds() *Box
+}
+
+//////////////////
+//public functions
+//////////////////
+
+//index lines in the bucket grid the layer is created with, the default. lines
+//outside the grid are held in its edge buckets, or lost if wholly outside
+func Grid_index() Option {
+	return func(l *Layer) {
+		l.new_index = func(l *Layer) Index {
+			return newgrid(l.width, l.height, l.scalex, l.scaley, false)
+		}
+	}
+}
+
+//keep buckets in a hash map instead of a width by height grid, only buckets with
+//lines in them take space, so lines can be anywhere including negative coordinates
+func Sparse_buckets() Option {
+	return func(l *Layer) {
+		l.new_index = func(l *Layer) Index {
+			return newgrid(l.width, l.height, l.scalex, l.scaley, true)
+		}
+	}
+}
+
+//index lines in a quadtree that grows to fit them, for lines of very mixed sizes
+func Quadtree_index() Option {
+	return func(l *Layer) {
+		l.new_index = func(l *Layer) Index {
+			return newquadtree(&Box{0.0, 0.0, float64(l.width) / float64(l.scalex), float64(l.height) / float64(l.scaley)})
+		}
+	}
+}
+
+//index lines in an r-tree, Add_Lines bulk loads it by sort tile recursive packing
+func Rtree_index() Option {
+	return func(l *Layer) {
+		l.new_index = func(l *Layer) Index {
+			return newrtree()
+		}
+	}
+}
+
+//use an index of your own
+func Custom_index(index Index) Option {
+	return func(l *Layer) {
+		l.new_index = func(l *Layer) Index {
+			return index
+		}
+	}
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//Hit for any index, from its Query
+func index_hit(index Index, box *Box, hit func(item interface{}) bool) interface{} {
+	var found interface{}
+	index.Query(box, func(item interface{}) bool {
+		if hit(item) {
+			found = item
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+//false for any NaN or infinite side
+func box_finite(b *Box) bool {
+	for _, x := range [4]float64{b.Minx, b.Miny, b.Maxx, b.Maxy} {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func box_overlaps(b1, b2 *Box) bool {
+	return (b1.Minx <= b2.Maxx) && (b1.Maxx >= b2.Minx) && (b1.Miny <= b2.Maxy) && (b1.Maxy >= b2.Miny)
+}
+
+//true if b1 holds all of b2
+func box_contains(b1, b2 *Box) bool {
+	return (b1.Minx <= b2.Minx) && (b1.Maxx >= b2.Maxx) && (b1.Miny <= b2.Miny) && (b1.Maxy >= b2.Maxy)
+}
+
+func box_union(b1, b2 *Box) *Box {
+	b := *b1
+	if b2.Minx < b.Minx {
+		b.Minx = b2.Minx
+	}
+	if b2.Miny < b.Miny {
+		b.Miny = b2.Miny
+	}
+	if b2.Maxx > b.Maxx {
+		b.Maxx = b2.Maxx
+	}
+	if b2.Maxy > b.Maxy {
+		b.Maxy = b2.Maxy
+	}
+	return &b
+}
+
+func box_area(b *Box) float64 {
+	return (b.Maxx - b.Minx) * (b.Maxy - b.Miny)
+}
@@ -0,0 +1,447 @@
+//package name
+package layer
+
+//package imports
+import (
+	"../mymath"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+/////////////////////////
+//private structure/types
+/////////////////////////
+
+//an index backend under test, bulk ones are first filled with Add_Lines
+type test_backend struct {
+	name string
+	opt  Option
+	bulk bool
+}
+
+type test_line struct {
+	line *Line
+	id   int
+}
+
+var test_backends = []test_backend{
+	{"grid", Grid_index(), false},
+	{"sparse", Sparse_buckets(), false},
+	{"quadtree", Quadtree_index(), false},
+	{"rtree", Rtree_index(), false},
+	{"rtree_bulk", Rtree_index(), true},
+}
+
+//a 1056 by 800 world in 16 unit buckets, as Newdlist makes for a window
+const (
+	test_cols  = 66
+	test_rows  = 50
+	test_scale = 1.0 / 16.0
+)
+
+///////////////////
+//private functions
+///////////////////
+
+func test_layer(backend test_backend) *Layer {
+	return Newlayer(test_cols, test_rows, test_scale, test_scale, backend.opt)
+}
+
+//mostly tiny pads with the odd long trace, kept clear of the world's edges
+func test_random_line(rng *rand.Rand) *Line {
+	clamp := func(x, max float32) float32 {
+		if x < 20.0 {
+			return 20.0
+		}
+		if x > max {
+			return max
+		}
+		return x
+	}
+	x1, y1 := 20.0+rng.Float32()*1000.0, 20.0+rng.Float32()*750.0
+	size, radius := float32(4.0), 0.5+rng.Float32()*2.0
+	if rng.Intn(10) == 0 {
+		size, radius = 800.0, 0.5+rng.Float32()
+	}
+	x2 := clamp(x1+(rng.Float32()-0.5)*size, 1020.0)
+	y2 := clamp(y1+(rng.Float32()-0.5)*size, 770.0)
+	gap := float32(0.0)
+	if rng.Intn(4) == 0 {
+		gap = rng.Float32()
+	}
+	return &Line{&Point{x1, y1}, &Point{x2, y2}, radius, gap, 0.0}
+}
+
+//ids of the live lines colliding with q, by brute force
+func test_brute_hits(live []*test_line, q *Line) map[int]bool {
+	ids := map[int]bool{}
+	for _, tl := range live {
+		if collide_lines(q, tl.line) {
+			ids[tl.id] = true
+		}
+	}
+	return ids
+}
+
+func test_copy_line(l *Line) *Line {
+	return &Line{&Point{l.P1.X, l.P1.Y}, &Point{l.P2.X, l.P2.Y}, l.Radius, l.Gap, l.Taper}
+}
+
+func test_fill(l *Layer, backend test_backend, lines []*Line, ids []int) {
+	if backend.bulk {
+		l.Add_Lines(lines, ids)
+		return
+	}
+	for i, line := range lines {
+		l.Add_Line(line, ids[i])
+	}
+}
+
+//a board outline round the world and n tiny pads inside it
+func test_board(rng *rand.Rand, n int) ([]*Line, []int) {
+	corners := []*Point{{10.0, 10.0}, {1030.0, 10.0}, {1030.0, 780.0}, {10.0, 780.0}}
+	lines := []*Line{}
+	ids := []int{}
+	for i := range corners {
+		lines = append(lines, &Line{corners[i], corners[(i+1)%len(corners)], 1.0, 0.0, 0.0})
+		ids = append(ids, 0)
+	}
+	for i := 1; i <= n; i++ {
+		x, y := 20.0+rng.Float32()*1000.0, 20.0+rng.Float32()*750.0
+		lines = append(lines, &Line{&Point{x, y}, &Point{x + 1.0, y}, 0.5, 0.0, 0.0})
+		ids = append(ids, i)
+	}
+	return lines, ids
+}
+
+///////
+//tests
+///////
+
+//every backend gives the answers a brute force scan of the live lines gives
+func TestIndex_conformance(t *testing.T) {
+	for _, backend := range test_backends {
+		t.Run(backend.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			l := test_layer(backend)
+			live := []*test_line{}
+			lines, ids := []*Line{}, []int{}
+			for i := 0; i < 500; i++ {
+				line := test_random_line(rng)
+				lines, ids = append(lines, line), append(ids, i%200)
+				live = append(live, &test_line{line, i % 200})
+			}
+			test_fill(l, backend, lines, ids)
+			for op := 0; op < 3000; op++ {
+				switch r := rng.Intn(10); {
+				case r < 4:
+					line := test_random_line(rng)
+					id := rng.Intn(200)
+					l.Add_Line(line, id)
+					live = append(live, &test_line{line, id})
+				case (r < 7) && (len(live) != 0):
+					//sub an equal line, not the one added
+					i := rng.Intn(len(live))
+					l.Sub_Line(test_copy_line(live[i].line), live[i].id)
+					live = append(live[:i], live[i+1:]...)
+				default:
+					q := test_random_line(rng)
+					want := test_brute_hits(live, q)
+					hit := l.Hit_Line(q)
+					if (hit == -1) != (len(want) == 0) || ((hit != -1) && !want[hit]) {
+						t.Fatalf("op %d: Hit_Line gave %d, brute force %v", op, hit, want)
+					}
+					all := l.Hit_Line_all(q, false)
+					seen := map[int]bool{}
+					for _, id := range all {
+						if seen[id] || !want[id] {
+							t.Fatalf("op %d: Hit_Line_all gave %v, brute force %v", op, all, want)
+						}
+						seen[id] = true
+					}
+					if len(seen) != len(want) {
+						t.Fatalf("op %d: Hit_Line_all gave %v, brute force %v", op, all, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+//queries report every live item whose box overlaps and never a removed one
+func TestIndex_query(t *testing.T) {
+	for _, backend := range test_backends {
+		t.Run(backend.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(2))
+			index := test_layer(backend).index
+			random_box := func() *Box {
+				x, y := 20.0+rng.Float64()*1000.0, 20.0+rng.Float64()*750.0
+				w, h := rng.Float64()*8.0, rng.Float64()*8.0
+				if rng.Intn(10) == 0 {
+					w, h = rng.Float64()*400.0, rng.Float64()*400.0
+				}
+				return &Box{x, y, x + w, y + h}
+			}
+			boxes := map[int]*Box{}
+			items, item_boxes := []interface{}{}, []*Box{}
+			next := 0
+			for ; next < 500; next++ {
+				boxes[next] = random_box()
+				items, item_boxes = append(items, next), append(item_boxes, boxes[next])
+			}
+			if backend.bulk {
+				index.Load(items, item_boxes)
+			} else {
+				for i, item := range items {
+					index.Add(item, item_boxes[i])
+				}
+			}
+			for op := 0; op < 3000; op++ {
+				switch r := rng.Intn(10); {
+				case r < 4:
+					boxes[next] = random_box()
+					index.Add(next, boxes[next])
+					next++
+				case (r < 7) && (len(boxes) != 0):
+					keys := []int{}
+					for item := range boxes {
+						keys = append(keys, item)
+					}
+					sort.Ints(keys)
+					item := keys[rng.Intn(len(keys))]
+					index.Sub(item, boxes[item])
+					delete(boxes, item)
+				default:
+					q := random_box()
+					found := map[int]bool{}
+					index.Query(q, func(item interface{}) bool {
+						if _, ok := boxes[item.(int)]; !ok {
+							t.Fatalf("op %d: Query gave removed item %d", op, item)
+						}
+						found[item.(int)] = true
+						return true
+					})
+					for item, box := range boxes {
+						if box_overlaps(q, box) && !found[item] {
+							t.Fatalf("op %d: Query missed item %d", op, item)
+						}
+					}
+				}
+			}
+			if (len(boxes) != 0) && (index.Bounds() == nil) {
+				t.Fatal("Bounds is nil with items in the index")
+			}
+		})
+	}
+}
+
+//nearest lines come back in the order a brute force scan of every line gives,
+//each id once at its nearest line, for queries inside and well outside the lines
+func TestNearest_Lines(t *testing.T) {
+	for _, backend := range test_backends {
+		t.Run(backend.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(7))
+			l := test_layer(backend)
+			if n := l.Nearest_Line(test_random_line(rng)); n != nil {
+				t.Fatalf("nearest %v in an empty layer", n)
+			}
+			lines, ids := []*Line{}, []int{}
+			for i := 0; i < 300; i++ {
+				//some ids have several lines
+				lines = append(lines, test_random_line(rng))
+				ids = append(ids, rng.Intn(200))
+			}
+			test_fill(l, backend, lines, ids)
+			for n := 0; n < 100; n++ {
+				q := test_random_line(rng)
+				if n%10 == 0 {
+					q = &Line{&Point{-500.0 + rng.Float32()*2500.0, 2000.0}, &Point{-500.0, -500.0 + rng.Float32()*2000.0}, 1.0, 0.0, 0.0}
+				}
+				ql := l.unit_line64(l.line_record(q, -1))
+				best := map[int]float32{}
+				for i, line := range lines {
+					d := nearest_lines64(ql, l.unit_line64(l.line_record(line, ids[i])), ids[i]).Distance
+					if old, ok := best[ids[i]]; !ok || (d < old) {
+						best[ids[i]] = d
+					}
+				}
+				want := []float32{}
+				for _, d := range best {
+					want = append(want, d)
+				}
+				sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+				for _, k := range []int{1, 5, 20, 1000} {
+					found := l.Nearest_Lines(q, k)
+					if len(found) != min_int(k, len(want)) {
+						t.Fatalf("query %d k %d: %d found, want %d", n, k, len(found), min_int(k, len(want)))
+					}
+					for i, f := range found {
+						if (f.Distance != want[i]) || (best[f.Id] != f.Distance) {
+							t.Fatalf("query %d k %d: %dth id %d at %v, want %v", n, k, i, f.Id, f.Distance, want[i])
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+//bounds hold every line, and a grid's shrink back to the buckets in use as
+//lines are taken out rather than staying at the most ever used
+func TestIndex_bounds(t *testing.T) {
+	near := &Line{&Point{100.0, 100.0}, &Point{110.0, 110.0}, 1.0, 0.0, 0.0}
+	far := &Line{&Point{900.0, 700.0}, &Point{950.0, 750.0}, 1.0, 0.0, 0.0}
+	for _, backend := range test_backends {
+		t.Run(backend.name, func(t *testing.T) {
+			l := test_layer(backend)
+			test_fill(l, backend, []*Line{near, far}, []int{1, 2})
+			b := l.index.Bounds()
+			if (b == nil) || !box_contains(b, l.box(l.line_record(near, 1))) || !box_contains(b, l.box(l.line_record(far, 2))) {
+				t.Fatalf("bounds %v do not hold both lines", b)
+			}
+			l.Sub_Line(far, 2)
+			b = l.index.Bounds()
+			if (b == nil) || !box_contains(b, l.box(l.line_record(near, 1))) {
+				t.Fatalf("bounds %v do not hold the line left", b)
+			}
+			if (backend.name == "grid" || backend.name == "sparse") && ((b.Maxx > 128.0) || (b.Maxy > 128.0)) {
+				t.Fatalf("bounds %v still reach the line taken out", b)
+			}
+			l.Add_Line(far, 2)
+			if b := l.index.Bounds(); !box_contains(b, l.box(l.line_record(far, 2))) {
+				t.Fatalf("bounds %v do not hold the line put back", b)
+			}
+		})
+	}
+}
+
+//lines with NaN or infinite coordinates are never indexed, by any backend, and
+//queries with them find nothing, rather than hanging or panicking
+func TestIndex_non_finite(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	bad := []*Line{
+		{&Point{nan, 100.0}, &Point{200.0, 100.0}, 1.0, 0.0, 0.0},
+		{&Point{100.0, 100.0}, &Point{200.0, nan}, 1.0, 0.0, 0.0},
+		{&Point{100.0, 100.0}, &Point{inf, 100.0}, 1.0, 0.0, 0.0},
+		{&Point{-inf, 100.0}, &Point{200.0, 100.0}, 1.0, 0.0, 0.0},
+		{&Point{100.0, 100.0}, &Point{200.0, 100.0}, nan, 0.0, 0.0},
+		{&Point{100.0, 100.0}, &Point{200.0, 100.0}, inf, 0.0, 0.0},
+	}
+	for _, backend := range test_backends {
+		t.Run(backend.name, func(t *testing.T) {
+			l := test_layer(backend)
+			good := &Line{&Point{100.0, 200.0}, &Point{200.0, 200.0}, 1.0, 0.0, 0.0}
+			test_fill(l, backend, []*Line{good}, []int{1})
+			bounds := *l.index.Bounds()
+			ids := make([]int, len(bad), len(bad))
+			for i := range bad {
+				ids[i] = 2 + i
+			}
+			test_fill(l, backend, bad, ids)
+			if b := l.index.Bounds(); (b == nil) || (*b != bounds) {
+				t.Fatalf("bounds %v after adding non finite lines, was %v", b, bounds)
+			}
+			if hit := l.Hit_Line(&Line{&Point{150.0, 90.0}, &Point{150.0, 210.0}, 1.0, 0.0, 0.0}); hit != 1 {
+				t.Fatalf("hit %d across every line, want only the finite one", hit)
+			}
+			all := l.Hit_Line_all(&Line{&Point{150.0, 90.0}, &Point{150.0, 210.0}, 1.0, 0.0, 0.0}, true)
+			if (len(all) != 1) || (all[0] != 1) {
+				t.Fatalf("hits %v across every line, want only the finite one", all)
+			}
+			for _, q := range bad {
+				if hit := l.Hit_Line(q); hit != -1 {
+					t.Fatalf("non finite query %v hit %d", q, hit)
+				}
+				if n := l.Nearest_Line(q); n != nil {
+					t.Fatalf("non finite query %v found nearest %v", q, n)
+				}
+			}
+			if ids := l.Hit_Rect(&mymath.Point{nan, 0.0}, &mymath.Point{1000.0, 1000.0}, false); len(ids) != 0 {
+				t.Fatalf("non finite rectangle hit %v", ids)
+			}
+			for i, q := range bad {
+				l.Sub_Line(q, ids[i])
+			}
+			l.Sub_Line(good, 1)
+			if b := l.index.Bounds(); b != nil {
+				t.Fatalf("bounds %v with every line taken out", b)
+			}
+		})
+	}
+}
+
+////////////
+//benchmarks
+////////////
+
+//one board outline round thousands of tiny pads, the mixed sizes a grid handles badly
+
+func BenchmarkIndex_add(b *testing.B) {
+	lines, ids := test_board(rand.New(rand.NewSource(3)), 5000)
+	for _, backend := range test_backends {
+		b.Run(backend.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				test_fill(test_layer(backend), backend, lines, ids)
+			}
+		})
+	}
+}
+
+func BenchmarkIndex_hit(b *testing.B) {
+	rng := rand.New(rand.NewSource(4))
+	lines, ids := test_board(rng, 5000)
+	queries := []*Line{}
+	for i := 0; i < 1000; i++ {
+		x, y := 20.0+rng.Float32()*1000.0, 20.0+rng.Float32()*750.0
+		queries = append(queries, &Line{&Point{x, y}, &Point{x, y}, 2.0, 0.0, 0.0})
+	}
+	for _, backend := range test_backends {
+		b.Run(backend.name, func(b *testing.B) {
+			l := test_layer(backend)
+			test_fill(l, backend, lines, ids)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Hit_Line(queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func BenchmarkIndex_move(b *testing.B) {
+	rng := rand.New(rand.NewSource(5))
+	lines, ids := test_board(rng, 5000)
+	for _, backend := range test_backends {
+		b.Run(backend.name, func(b *testing.B) {
+			l := test_layer(backend)
+			test_fill(l, backend, lines, ids)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				j := 4 + i%(len(lines)-4)
+				l.Sub_Line(lines[j], ids[j])
+				l.Add_Line(lines[j], ids[j])
+			}
+		})
+	}
+}
+
+func BenchmarkIndex_rect(b *testing.B) {
+	rng := rand.New(rand.NewSource(6))
+	lines, ids := test_board(rng, 5000)
+	for _, backend := range test_backends {
+		b.Run(backend.name, func(b *testing.B) {
+			l := test_layer(backend)
+			test_fill(l, backend, lines, ids)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				x, y := float32(20+(i*37)%900), float32(20+(i*53)%650)
+				l.Hit_Rect(&mymath.Point{x, y}, &mymath.Point{x + 100.0, y + 100.0}, false)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ package layer
 //package imports
 import (
 	"../mymath"
+	"fmt"
 	"math"
+	"sort"
 )
 
 ////////////////////////
@@ -16,140 +18,436 @@ type Point struct {
 	Y float32
 }
 
+//Taper is the change in radius from P1 to P2, zero for a uniform line
 type Line struct {
 	P1     *Point
 	P2     *Point
 	Radius float32
 	Gap    float32
+	Taper  float32
 }
 
+//a line in float64, for geometry too large or far out for float32
+type Line64 struct {
+	P1     mymath.Vec64
+	P2     mymath.Vec64
+	Radius float64
+	Gap    float64
+	Taper  float64
+}
+
+//a line in the integer units of a layer made with Int64_coords
+type Line_i64 struct {
+	P1     mymath.Ipoint
+	P2     mymath.Ipoint
+	Radius int64
+	Gap    int64
+	Taper  int64
+}
+
+type Hit struct {
+	Id      int
+	Line    *Line
+	Contact *mymath.Contact
+}
+
+//result of a nearest query, Distance is between the surfaces of the lines and is
+//negative when they overlap, Point is the closest point on the found line's centre
+type Nearest struct {
+	Id       int
+	Distance float32
+	Point    *mymath.Point
+}
+
+//a layer option, passed to Newlayer
+type Option func(*Layer)
+
 /////////////////////////
 //private structure/types
 /////////////////////////
 
+//line is always set, float64 and integer layers also hold the line they test with
 type record struct {
-	count int
-	id    int
-	line  *Line
-}
-
-type aabb struct {
-	minx int
-	miny int
-	maxx int
-	maxy int
+	count    int
+	id       int
+	line     *Line
+	line64   *Line64
+	line_i64 *Line_i64
 }
 
-type bucket []*record
-type buckets []bucket
+//coordinate modes
+const (
+	coords_float32 = iota
+	coords_float64
+	coords_int64
+)
 
 //////////////
 //Layer object
 //////////////
 
 type Layer struct {
-	width   int
-	height  int
-	scalex  float32
-	scaley  float32
-	buckets buckets
-	count   int
+	width     int
+	height    int
+	scalex    float32
+	scaley    float32
+	index     Index
+	new_index func(l *Layer) Index
+	records   map[int][]*record
+	count     int
+	coords    int
+	units     float64
 }
 
 ////////////////
 //public methods
 ////////////////
 
-func Newlayer(width, height int, sx, sy float32) *Layer {
+func Newlayer(width, height int, sx, sy float32, opts ...Option) *Layer {
 	l := Layer{}
 	l.init(width, height, sx, sy)
+	for _, opt := range opts {
+		opt(&l)
+	}
+	l.index = l.new_index(&l)
 	return &l
 }
 
+//hold and test collision lines in float64, transforms are applied in float64 too
+func Float64_coords() Option {
+	return func(l *Layer) {
+		l.coords = coords_float64
+	}
+}
+
+//hold collision lines in integer fixed point, units per coordinate unit, so 1000000
+//for nanometres when coordinates are millimetres. uniform lines are tested with exact
+//integer predicates, tapered lines in float64. units must be positive and finite
+func Int64_coords(units float64) Option {
+	if !(units > 0.0) || math.IsInf(units, 1) {
+		panic(fmt.Sprintf("layer: Int64_coords units must be positive and finite, not %v", units))
+	}
+	return func(l *Layer) {
+		l.coords = coords_int64
+		l.units = units
+	}
+}
+
 func (self *Layer) Add_Line(l *Line, id int) {
-	new_record := record{0, id, l}
-	bb := self.aabb(l)
-	for y := bb.miny; y < bb.maxy; y++ {
-		for x := bb.minx; x < bb.maxx; x++ {
-			b := y*self.width + x
-			self.buckets[b] = append(self.buckets[b], &new_record)
+	self.add_record(self.line_record(l, id))
+}
+
+func (self *Layer) Sub_Line(l *Line, id int) {
+	self.sub_record(self.line_record(l, id))
+}
+
+func (self *Layer) Hit_Line(l *Line) int {
+	return self.hit_record(self.line_record(l, -1))
+}
+
+//float64 lines keep their precision in float64 and integer layers
+func (self *Layer) Add_Line64(l *Line64, id int) {
+	self.add_record(self.exact_record(l, id))
+}
+
+func (self *Layer) Sub_Line64(l *Line64, id int) {
+	self.sub_record(self.exact_record(l, id))
+}
+
+func (self *Layer) Hit_Line64(l *Line64) int {
+	return self.hit_record(self.exact_record(l, -1))
+}
+
+//integer lines are taken as they are by integer layers, other layers divide them by Units
+func (self *Layer) Add_Line_i64(l *Line_i64, id int) {
+	self.add_record(self.int_record(l, id))
+}
+
+func (self *Layer) Sub_Line_i64(l *Line_i64, id int) {
+	self.sub_record(self.int_record(l, id))
+}
+
+func (self *Layer) Hit_Line_i64(l *Line_i64) int {
+	return self.hit_record(self.int_record(l, -1))
+}
+
+//integer units per coordinate unit, 1 unless made with Int64_coords
+func (self *Layer) Units() float64 {
+	return self.units
+}
+
+//every id with a line colliding with l, each once. sorted orders them by the distance
+//between centre lines, nearest first, otherwise they come in the order found
+func (self *Layer) Hit_Line_all(l *Line, sorted bool) []int {
+	ids := []int{}
+	distances := map[int]float64{}
+	q := self.line_record(l, -1)
+	self.hit_records(q, func(record *record) bool {
+		d := 0.0
+		if sorted {
+			c := self.contact(q, record)
+			d = float64(mymath.Distance_2d(c.P1, c.P2))
 		}
+		if old, ok := distances[record.id]; ok {
+			distances[record.id] = math.Min(old, d)
+			return true
+		}
+		distances[record.id] = d
+		ids = append(ids, record.id)
+		return true
+	})
+	if sorted {
+		sort.SliceStable(ids, func(i, j int) bool { return distances[ids[i]] < distances[ids[j]] })
 	}
+	return ids
 }
 
-func (self *Layer) Sub_Line(l *Line, id int) {
-	bb := self.aabb(l)
-	for y := bb.miny; y < bb.maxy; y++ {
-		for x := bb.minx; x < bb.maxx; x++ {
-			b := y*self.width + x
-			for i := len(self.buckets[b]) - 1; i >= 0; i-- {
-				record := self.buckets[b][i]
-				if record.id == id {
-					if lines_equal(record.line, l) {
-						self.buckets[b] = append(self.buckets[b][:i], self.buckets[b][i+1:]...)
-						break
-					}
-				}
+//call f with every id with a line colliding with l, each once, until f returns false
+func (self *Layer) Hit_Line_each(l *Line, f func(id int) bool) {
+	seen := map[int]bool{}
+	self.hit_records(self.line_record(l, -1), func(record *record) bool {
+		if seen[record.id] {
+			return true
+		}
+		seen[record.id] = true
+		return f(record.id)
+	})
+}
+
+func (self *Layer) Hit_Line_contacts(l *Line) []*Hit {
+	hits := []*Hit{}
+	q := self.line_record(l, -1)
+	self.hit_records(q, func(record *record) bool {
+		hits = append(hits, &Hit{record.id, record.line, self.contact(q, record)})
+		return true
+	})
+	return hits
+}
+
+//add many lines at once, ids[i] for lines[i]. an r-tree packs them better than
+//adding them one at a time
+func (self *Layer) Add_Lines(lines []*Line, ids []int) {
+	items := make([]interface{}, len(lines), len(lines))
+	boxes := make([]*Box, len(lines), len(lines))
+	for i, l := range lines {
+		r := self.line_record(l, ids[i])
+		self.records[r.id] = append(self.records[r.id], r)
+		items[i], boxes[i] = r, self.box(r)
+	}
+	self.index.Load(items, boxes)
+}
+
+//the id with a line nearest to l, searching out from l ring by ring a bucket wide,
+//nil if the layer is empty. gaps play no part
+func (self *Layer) Nearest_Line(l *Line) *Nearest {
+	nearest := self.Nearest_Lines(l, 1)
+	if len(nearest) == 0 {
+		return nil
+	}
+	return nearest[0]
+}
+
+//the k ids with lines nearest to l, nearest first, each once at its nearest line
+func (self *Layer) Nearest_Lines(l *Line, k int) []*Nearest {
+	self.count += 1
+	q := self.line_record(l, -1)
+	ql := self.unit_line64(q)
+	best := map[int]*Nearest{}
+	found := []*Nearest{}
+	bb := self.box(q)
+	lb := self.index.Bounds()
+	if (lb == nil) || !box_finite(bb) {
+		return found
+	}
+	//rings a bucket wide, an unseen record after ring r is at least r buckets from the query
+	cx, cy := 1.0/float64(self.scalex), 1.0/float64(self.scaley)
+	cell := math.Min(cx, cy)
+	ring := func(r int) *Box {
+		return &Box{bb.Minx - float64(r)*cx, bb.Miny - float64(r)*cy, bb.Maxx + float64(r)*cx, bb.Maxy + float64(r)*cy}
+	}
+	first := math.Max(math.Max((lb.Minx-bb.Maxx)/cx, (bb.Minx-lb.Maxx)/cx), math.Max((lb.Miny-bb.Maxy)/cy, (bb.Miny-lb.Maxy)/cy))
+	visit := func(item interface{}) bool {
+		record := item.(*record)
+		if record.count != self.count {
+			record.count = self.count
+			n := nearest_lines64(ql, self.unit_line64(record), record.id)
+			if old, ok := best[record.id]; !ok || (n.Distance < old.Distance) {
+				best[record.id] = n
+			}
+		}
+		return true
+	}
+	for r := max_int(int(first), 0); k > 0; r++ {
+		outer := ring(r)
+		if r == max_int(int(first), 0) {
+			self.index.Query(outer, visit)
+		} else {
+			//the four strips between this ring and the last
+			inner := ring(r - 1)
+			self.index.Query(&Box{outer.Minx, outer.Miny, outer.Maxx, inner.Miny}, visit)
+			self.index.Query(&Box{outer.Minx, inner.Maxy, outer.Maxx, outer.Maxy}, visit)
+			self.index.Query(&Box{outer.Minx, inner.Miny, inner.Minx, inner.Maxy}, visit)
+			self.index.Query(&Box{inner.Maxx, inner.Miny, outer.Maxx, inner.Maxy}, visit)
+		}
+		found = found[:0]
+		for _, n := range best {
+			found = append(found, n)
+		}
+		sort.Slice(found, func(i, j int) bool {
+			if found[i].Distance != found[j].Distance {
+				return found[i].Distance < found[j].Distance
 			}
+			return found[i].Id < found[j].Id
+		})
+		if (len(found) >= k) && (float64(found[k-1].Distance) <= float64(r)*cell) {
+			break
+		}
+		if box_contains(outer, lb) {
+			break
 		}
 	}
+	if len(found) > k {
+		found = found[:k]
+	}
+	return found
 }
 
-func (self *Layer) Hit_Line(l *Line) int {
+//ids with lines touching the rectangle with corners p1 and p2, or when contained
+//only ids with every line inside it. a line's gap counts as part of it, as it
+//does against other lines
+func (self *Layer) Hit_Rect(p1, p2 *mymath.Point, contained bool) []int {
+	minp, maxp := mymath.Path_bounds(&mymath.Points{p1, p2})
+	min, max := *minp, *maxp
+	return self.Hit_Polygon(&mymath.Points{&mymath.Point{min[0], min[1]}, &mymath.Point{max[0], min[1]},
+		&mymath.Point{max[0], max[1]}, &mymath.Point{min[0], max[1]}}, contained)
+}
+
+//ids with lines touching the closed polygon, or when contained only ids with
+//every line inside it, gaps as for Hit_Rect. the polygon is filled by the non-zero rule
+func (self *Layer) Hit_Polygon(pathp *mymath.Points, contained bool) []int {
+	ids := []int{}
+	path := *pathp
+	if len(path) == 0 {
+		return ids
+	}
+	edges := [][2]mymath.Vec64{}
+	for i, pp := range path {
+		p1, p2 := *pp, *path[(i+1)%len(path)]
+		edges = append(edges, [2]mymath.Vec64{{float64(p1[0]), float64(p1[1])}, {float64(p2[0]), float64(p2[1])}})
+	}
+	minp, maxp := mymath.Path_bounds(pathp)
+	min, max := *minp, *maxp
+	q := self.line_record(&Line{&Point{min[0], min[1]}, &Point{max[0], max[1]}, 0.0, 0.0, 0.0}, -1)
+	seen := map[int]bool{}
 	self.count += 1
-	bb := self.aabb(l)
-	l1_p1 := mymath.Point{l.P1.X, l.P1.Y}
-	l1_p2 := mymath.Point{l.P2.X, l.P2.Y}
-	l2_p1 := mymath.Point{0.0, 0.0}
-	l2_p2 := mymath.Point{0.0, 0.0}
-	for y := bb.miny; y < bb.maxy; y++ {
-		for x := bb.minx; x < bb.maxx; x++ {
-			for _, record := range self.buckets[y*self.width+x] {
-				if record.count != self.count {
-					record.count = self.count
-					r := l.Radius + record.line.Radius
-					if l.Gap >= record.line.Gap {
-						r += l.Gap
-					} else {
-						r += record.line.Gap
-					}
-					l2_p1[0], l2_p1[1] = record.line.P1.X, record.line.P1.Y
-					l2_p2[0], l2_p2[1] = record.line.P2.X, record.line.P2.Y
-					if mymath.Collide_thick_lines_2d(&l1_p1, &l1_p2, &l2_p1, &l2_p2, r) {
-						return record.id
-					}
+	self.each_record(self.box(q), func(record *record) bool {
+		if seen[record.id] {
+			return true
+		}
+		if contained {
+			if !self.region_contains(pathp, edges, record) {
+				return true
+			}
+			for _, r := range self.records[record.id] {
+				if !self.region_contains(pathp, edges, r) {
+					seen[record.id] = true
+					return true
 				}
 			}
+		} else if !self.region_touches(pathp, edges, record) {
+			return true
 		}
-	}
-	return -1
+		seen[record.id] = true
+		ids = append(ids, record.id)
+		return true
+	})
+	return ids
 }
 
 func (self *Layer) Add_path(offsetp *mymath.Point, pathp *mymath.Points, radius, gap float32, id int) {
-	path, offset := *pathp, *offsetp
-	pp1 := path[0]
-	p1 := *pp1
-	lp1 := &Point{p1[0] + offset[0], p1[1] + offset[1]}
-	for i := 1; i < len(path); i++ {
-		lp0 := lp1
-		pp1 = path[i]
-		p1 = *pp1
-		lp1 = &Point{p1[0] + offset[0], p1[1] + offset[1]}
-		self.Add_Line(&Line{lp0, lp1, radius, gap}, id)
-	}
+	offset := *offsetp
+	self.Add_transformed_path(mymath.Translate_matrix(offset[0], offset[1]), pathp, radius, gap, id)
 }
 
 func (self *Layer) Sub_path(offsetp *mymath.Point, pathp *mymath.Points, radius, gap float32, id int) {
-	path, offset := *pathp, *offsetp
-	pp1 := path[0]
-	p1 := *pp1
-	lp1 := &Point{p1[0] + offset[0], p1[1] + offset[1]}
-	for i := 1; i < len(path); i++ {
-		lp0 := lp1
-		pp1 = path[i]
-		p1 = *pp1
-		lp1 = &Point{p1[0] + offset[0], p1[1] + offset[1]}
-		self.Sub_Line(&Line{lp0, lp1, radius, gap}, id)
+	offset := *offsetp
+	self.Sub_transformed_path(mymath.Translate_matrix(offset[0], offset[1]), pathp, radius, gap, id)
+}
+
+//a radius per path point, fitted to the path as mymath.Fit_radii does
+func (self *Layer) Add_variable_path(offsetp *mymath.Point, pathp *mymath.Points, radii []float32, gap float32, id int) {
+	offset := *offsetp
+	self.Add_transformed_variable_path(mymath.Translate_matrix(offset[0], offset[1]), pathp, radii, gap, id)
+}
+
+func (self *Layer) Sub_variable_path(offsetp *mymath.Point, pathp *mymath.Points, radii []float32, gap float32, id int) {
+	offset := *offsetp
+	self.Sub_transformed_variable_path(mymath.Translate_matrix(offset[0], offset[1]), pathp, radii, gap, id)
+}
+
+//radii scale with the transform, the gap does not. under a non uniform scale each
+//line keeps the width the transformed stroke has across it, but its round ends
+//stay round rather than stretching to ellipses
+func (self *Layer) Add_transformed_path(transform *mymath.Matrix, pathp *mymath.Points, radius, gap float32, id int) {
+	for _, r := range self.path_records(transform, pathp, radius, nil, gap, id) {
+		self.add_record(r)
+	}
+}
+
+func (self *Layer) Sub_transformed_path(transform *mymath.Matrix, pathp *mymath.Points, radius, gap float32, id int) {
+	for _, r := range self.path_records(transform, pathp, radius, nil, gap, id) {
+		self.sub_record(r)
+	}
+}
+
+func (self *Layer) Add_transformed_variable_path(transform *mymath.Matrix, pathp *mymath.Points, radii []float32, gap float32, id int) {
+	for _, r := range self.path_records(transform, pathp, 0.0, radii, gap, id) {
+		self.add_record(r)
+	}
+}
+
+func (self *Layer) Sub_transformed_variable_path(transform *mymath.Matrix, pathp *mymath.Points, radii []float32, gap float32, id int) {
+	for _, r := range self.path_records(transform, pathp, 0.0, radii, gap, id) {
+		self.sub_record(r)
+	}
+}
+
+//float64 paths are transformed in float64 and keep their precision in float64 and
+//integer layers, radii scale as for Add_transformed_path
+func (self *Layer) Add_transformed_path64(transform *mymath.Matrix64, path []mymath.Vec64, radius, gap float64, id int) {
+	for _, r := range self.path_records64(transform, path, radius, nil, gap, id) {
+		self.add_record(r)
+	}
+}
+
+func (self *Layer) Sub_transformed_path64(transform *mymath.Matrix64, path []mymath.Vec64, radius, gap float64, id int) {
+	for _, r := range self.path_records64(transform, path, radius, nil, gap, id) {
+		self.sub_record(r)
+	}
+}
+
+func (self *Layer) Add_transformed_variable_path64(transform *mymath.Matrix64, path []mymath.Vec64, radii []float64, gap float64, id int) {
+	for _, r := range self.path_records64(transform, path, 0.0, radii, gap, id) {
+		self.add_record(r)
+	}
+}
+
+func (self *Layer) Sub_transformed_variable_path64(transform *mymath.Matrix64, path []mymath.Vec64, radii []float64, gap float64, id int) {
+	for _, r := range self.path_records64(transform, path, 0.0, radii, gap, id) {
+		self.sub_record(r)
+	}
+}
+
+//integer paths move by an integer offset, so an integer layer holds them exactly
+func (self *Layer) Add_path_i64(offset mymath.Ipoint, path []mymath.Ipoint, radius, gap int64, id int) {
+	for _, r := range self.path_records_i64(offset, path, radius, gap, id) {
+		self.add_record(r)
+	}
+}
+
+func (self *Layer) Sub_path_i64(offset mymath.Ipoint, path []mymath.Ipoint, radius, gap int64, id int) {
+	for _, r := range self.path_records_i64(offset, path, radius, gap, id) {
+		self.sub_record(r)
 	}
 }
 
@@ -162,40 +460,305 @@ func (self *Layer) init(width, height int, sx, sy float32) {
 	self.height = height
 	self.scalex = sx
 	self.scaley = sy
-	self.buckets = make(buckets, (width * height), (width * height))
-	for i := 0; i < (width * height); i++ {
-		self.buckets[i] = bucket{}
+	self.index = nil
+	self.new_index = func(l *Layer) Index {
+		return newgrid(l.width, l.height, l.scalex, l.scaley, false)
 	}
+	self.records = map[int][]*record{}
 	self.count = 0
+	self.coords = coords_float32
+	self.units = 1.0
 	return
 }
 
-func (self *Layer) aabb(l *Line) *aabb {
-	x1, y1, x2, y2 := l.P1.X, l.P1.Y, l.P2.X, l.P2.Y
-	if x1 > x2 {
-		x1, x2 = x2, x1
+func (self *Layer) add_record(new_record *record) {
+	self.records[new_record.id] = append(self.records[new_record.id], new_record)
+	self.index.Add(new_record, self.box(new_record))
+}
+
+//removes the first record held for the id that equals old_record
+func (self *Layer) sub_record(old_record *record) {
+	records := self.records[old_record.id]
+	for i, record := range records {
+		if records_equal(record, old_record) {
+			self.index.Sub(record, self.box(record))
+			records = append(records[:i], records[i+1:]...)
+			break
+		}
 	}
-	if y1 > y2 {
-		y1, y2 = y2, y1
+	if len(records) == 0 {
+		delete(self.records, old_record.id)
+	} else {
+		self.records[old_record.id] = records
 	}
-	r := l.Radius + l.Gap
-	minx := int(math.Floor(float64((x1 - r) * self.scalex)))
-	miny := int(math.Floor(float64((y1 - r) * self.scaley)))
-	maxx := int(math.Ceil(float64((x2 + r) * self.scalex)))
-	maxy := int(math.Ceil(float64((y2 + r) * self.scaley)))
-	if minx < 0 {
-		minx = 0
+}
+
+//record for a line in the layer's coordinate mode
+func (self *Layer) line_record(l *Line, id int) *record {
+	if self.coords == coords_float32 {
+		return &record{0, id, l, nil, nil}
+	}
+	return self.exact_record(&Line64{mymath.Vec64{float64(l.P1.X), float64(l.P1.Y)},
+		mymath.Vec64{float64(l.P2.X), float64(l.P2.Y)},
+		float64(l.Radius), float64(l.Gap), float64(l.Taper)}, id)
+}
+
+//record for a float64 line, float32 layers only keep it in float32
+func (self *Layer) exact_record(l *Line64, id int) *record {
+	line := &Line{&Point{float32(l.P1.X), float32(l.P1.Y)}, &Point{float32(l.P2.X), float32(l.P2.Y)},
+		float32(l.Radius), float32(l.Gap), float32(l.Taper)}
+	switch self.coords {
+	case coords_float32:
+		return &record{0, id, line, nil, nil}
+	case coords_float64:
+		return &record{0, id, line, l, nil}
+	}
+	u := func(x float64) int64 { return int64(math.Round(x * self.units)) }
+	li := &Line_i64{mymath.Ipoint{u(l.P1.X), u(l.P1.Y)}, mymath.Ipoint{u(l.P2.X), u(l.P2.Y)},
+		u(l.Radius), u(l.Gap), u(l.Taper)}
+	return &record{0, id, line, nil, li}
+}
+
+//record for an integer line, other layers take it in coordinate units
+func (self *Layer) int_record(l *Line_i64, id int) *record {
+	if self.coords != coords_int64 {
+		return self.exact_record(self.unit_line(l), id)
+	}
+	ul := self.unit_line(l)
+	line := &Line{&Point{float32(ul.P1.X), float32(ul.P1.Y)}, &Point{float32(ul.P2.X), float32(ul.P2.Y)},
+		float32(ul.Radius), float32(ul.Gap), float32(ul.Taper)}
+	return &record{0, id, line, nil, l}
+}
+
+//records for the lines of a transformed path, radius is used when radii is nil.
+//float64 and integer layers transform in float64
+func (self *Layer) path_records(transform *mymath.Matrix, pathp *mymath.Points, radius float32, radii []float32, gap float32, id int) []*record {
+	records := []*record{}
+	if self.coords == coords_float32 {
+		for _, l := range transform_lines(transform, pathp, radius, radii, gap) {
+			records = append(records, &record{0, id, l, nil, nil})
+		}
+		return records
+	}
+	path := make([]mymath.Vec64, len(*pathp), len(*pathp))
+	for i, pp := range *pathp {
+		p := *pp
+		path[i] = mymath.Vec64{float64(p[0]), float64(p[1])}
+	}
+	var radii64 []float64
+	if radii != nil {
+		radii64 = make([]float64, len(radii), len(radii))
+		for i, r := range radii {
+			radii64[i] = float64(r)
+		}
+	}
+	return self.path_records64(mymath.Matrix_to_matrix64(transform), path, float64(radius), radii64, float64(gap), id)
+}
+
+func (self *Layer) path_records64(transform *mymath.Matrix64, path []mymath.Vec64, radius float64, radii []float64, gap float64, id int) []*record {
+	records := []*record{}
+	if len(path) == 0 {
+		return records
+	}
+	if radii != nil {
+		radii = fit_radii64(radii, len(path))
+	}
+	p1 := mymath.Transform_vec64(transform, path[0])
+	for i := 1; i < len(path); i++ {
+		p0 := p1
+		p1 = mymath.Transform_vec64(transform, path[i])
+		scale := mymath.Matrix64_line_scale(transform, path[i-1], path[i])
+		l := &Line64{p0, p1, radius * scale, gap, 0.0}
+		if radii != nil {
+			l.Radius = radii[i-1] * scale
+			l.Taper = (radii[i] - radii[i-1]) * scale
+		}
+		records = append(records, self.exact_record(l, id))
+	}
+	return records
+}
+
+func (self *Layer) path_records_i64(offset mymath.Ipoint, path []mymath.Ipoint, radius, gap int64, id int) []*record {
+	records := []*record{}
+	for i := 1; i < len(path); i++ {
+		p0 := mymath.Ipoint{path[i-1].X + offset.X, path[i-1].Y + offset.Y}
+		p1 := mymath.Ipoint{path[i].X + offset.X, path[i].Y + offset.Y}
+		records = append(records, self.int_record(&Line_i64{p0, p1, radius, gap, 0}, id))
+	}
+	return records
+}
+
+//the id of a record colliding with q, -1 if none
+func (self *Layer) hit_record(q *record) int {
+	self.count += 1
+	hit := self.index.Hit(self.box(q), func(item interface{}) bool {
+		record := item.(*record)
+		if record.count != self.count {
+			record.count = self.count
+			return self.collide(q, record)
+		}
+		return false
+	})
+	if hit == nil {
+		return -1
+	}
+	return hit.(*record).id
+}
+
+//call f with each record colliding with q until f returns false
+func (self *Layer) hit_records(q *record, f func(record *record) bool) {
+	self.count += 1
+	self.each_record(self.box(q), func(record *record) bool {
+		if self.collide(q, record) {
+			return f(record)
+		}
+		return true
+	})
+}
+
+//call f with each record near the box not yet seen this count, until f returns false
+func (self *Layer) each_record(box *Box, f func(record *record) bool) {
+	self.index.Query(box, func(item interface{}) bool {
+		record := item.(*record)
+		if record.count != self.count {
+			record.count = self.count
+			return f(record)
+		}
+		return true
+	})
+}
+
+//the thick line, gap and all, crosses the polygon's edges or starts inside it
+func (self *Layer) region_touches(pathp *mymath.Points, edges [][2]mymath.Vec64, r *record) bool {
+	l := self.unit_line64(r)
+	if mymath.Point_in_polygon(&mymath.Point{float32(l.P1.X), float32(l.P1.Y)}, pathp, 1) {
+		return true
 	}
-	if miny < 0 {
-		miny = 0
+	return region_edges_hit(edges, l)
+}
+
+//the whole outline of the thick line, gap and all, is inside the polygon. both
+//ends are inside and no edge comes within the outline, so no part of it can
+//cross out, not even where the polygon folds in between the ends
+func (self *Layer) region_contains(pathp *mymath.Points, edges [][2]mymath.Vec64, r *record) bool {
+	l := self.unit_line64(r)
+	for _, p := range []mymath.Vec64{l.P1, l.P2} {
+		if !mymath.Point_in_polygon(&mymath.Point{float32(p.X), float32(p.Y)}, pathp, 1) {
+			return false
+		}
 	}
-	if maxx > self.width {
-		maxx = self.width
+	return !region_edges_hit(edges, l)
+}
+
+func (self *Layer) collide(r1, r2 *record) bool {
+	switch self.coords {
+	case coords_float64:
+		return collide_lines64(r1.line64, r2.line64)
+	case coords_int64:
+		return collide_lines_i64(r1.line_i64, r2.line_i64)
 	}
-	if maxy > self.height {
-		maxy = self.height
+	return collide_lines(r1.line, r2.line)
+}
+
+//contacts are always in coordinate units
+func (self *Layer) contact(r1, r2 *record) *mymath.Contact {
+	switch self.coords {
+	case coords_float64:
+		return contact_lines64(r1.line64, r2.line64)
+	case coords_int64:
+		return contact_lines64(self.unit_line(r1.line_i64), self.unit_line(r2.line_i64))
 	}
-	return &aabb{minx, miny, maxx, maxy}
+	return contact_lines(r1.line, r2.line)
+}
+
+//integer line back in float64 coordinate units
+func (self *Layer) unit_line(l *Line_i64) *Line64 {
+	u := func(x int64) float64 { return float64(x) / self.units }
+	return &Line64{mymath.Vec64{u(l.P1.X), u(l.P1.Y)}, mymath.Vec64{u(l.P2.X), u(l.P2.Y)},
+		u(l.Radius), u(l.Gap), u(l.Taper)}
+}
+
+//any record's line in float64 coordinate units
+func (self *Layer) unit_line64(r *record) *Line64 {
+	switch {
+	case r.line64 != nil:
+		return r.line64
+	case r.line_i64 != nil:
+		return self.unit_line(r.line_i64)
+	}
+	l := r.line
+	return &Line64{mymath.Vec64{float64(l.P1.X), float64(l.P1.Y)}, mymath.Vec64{float64(l.P2.X), float64(l.P2.Y)},
+		float64(l.Radius), float64(l.Gap), float64(l.Taper)}
+}
+
+//box round a record's line, its radius, gap and any taper
+func (self *Layer) box(r *record) *Box {
+	if r.line64 == nil && r.line_i64 == nil {
+		l := r.line
+		x1, y1, x2, y2 := l.P1.X, l.P1.Y, l.P2.X, l.P2.Y
+		if x1 > x2 {
+			x1, x2 = x2, x1
+		}
+		if y1 > y2 {
+			y1, y2 = y2, y1
+		}
+		rad := l.Radius + l.Gap
+		if l.Taper > 0.0 {
+			rad += l.Taper
+		}
+		return &Box{float64(x1 - rad), float64(y1 - rad), float64(x2 + rad), float64(y2 + rad)}
+	}
+	l := self.unit_line64(r)
+	rad := l.Radius + l.Gap + math.Max(0.0, l.Taper)
+	return &Box{math.Min(l.P1.X, l.P2.X) - rad, math.Min(l.P1.Y, l.P2.Y) - rad,
+		math.Max(l.P1.X, l.P2.X) + rad, math.Max(l.P1.Y, l.P2.Y) + rad}
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//the lines of a transformed path, radius is used when radii is nil. each line's
+//radius scales by how much the transform widens it, see mymath.Matrix_line_scale
+func transform_lines(transform *mymath.Matrix, pathp *mymath.Points, radius float32, radii []float32, gap float32) []*Line {
+	path := *pathp
+	lines := []*Line{}
+	if len(path) == 0 {
+		return lines
+	}
+	if radii != nil {
+		radii = mymath.Fit_radii(radii, len(path))
+	}
+	p1 := *mymath.Transform_point(transform, path[0])
+	lp1 := &Point{p1[0], p1[1]}
+	for i := 1; i < len(path); i++ {
+		lp0 := lp1
+		p1 = *mymath.Transform_point(transform, path[i])
+		lp1 = &Point{p1[0], p1[1]}
+		scale := mymath.Matrix_line_scale(transform, path[i-1], path[i])
+		if radii != nil {
+			lines = append(lines, &Line{lp0, lp1, radii[i-1] * scale, gap, (radii[i] - radii[i-1]) * scale})
+		} else {
+			lines = append(lines, &Line{lp0, lp1, radius * scale, gap, 0.0})
+		}
+	}
+	return lines
+}
+
+//float64 radii fitted to n points, see mymath.Fit_radii
+func fit_radii64(radii []float64, n int) []float64 {
+	if len(radii) == n {
+		return radii
+	}
+	out := make([]float64, n, n)
+	last := 0.0
+	for i := range out {
+		if i < len(radii) {
+			last = radii[i]
+		}
+		out[i] = last
+	}
+	return out
 }
 
 func lines_equal(l1, l2 *Line) bool {
@@ -220,5 +783,139 @@ func lines_equal(l1, l2 *Line) bool {
 	if l1.Gap != l2.Gap {
 		return false
 	}
+	if l1.Taper != l2.Taper {
+		return false
+	}
 	return true
 }
+
+func records_equal(r1, r2 *record) bool {
+	switch {
+	case (r1.line64 != nil) && (r2.line64 != nil):
+		return *r1.line64 == *r2.line64
+	case (r1.line_i64 != nil) && (r2.line_i64 != nil):
+		return *r1.line_i64 == *r2.line_i64
+	}
+	return lines_equal(r1.line, r2.line)
+}
+
+func collide_lines(l1, l2 *Line) bool {
+	l1_p1 := mymath.Vec2{l1.P1.X, l1.P1.Y}
+	l1_p2 := mymath.Vec2{l1.P2.X, l1.P2.Y}
+	l2_p1 := mymath.Vec2{l2.P1.X, l2.P1.Y}
+	l2_p2 := mymath.Vec2{l2.P2.X, l2.P2.Y}
+	gap := l1.Gap
+	if l2.Gap > gap {
+		gap = l2.Gap
+	}
+	if (l1.Taper == 0.0) && (l2.Taper == 0.0) {
+		return mymath.Collide_thick_lines_vec2(l1_p1, l1_p2, l2_p1, l2_p2, l1.Radius+l2.Radius+gap)
+	}
+	return mymath.Collide_tapered_lines_vec2(l1_p1, l1_p2, l1.Radius+gap, l1.Radius+l1.Taper+gap,
+		l2_p1, l2_p2, l2.Radius, l2.Radius+l2.Taper)
+}
+
+//contact between lines, tapered radii are taken at the closest points
+func contact_lines(l1, l2 *Line) *mymath.Contact {
+	l1_p1 := mymath.Point{l1.P1.X, l1.P1.Y}
+	l1_p2 := mymath.Point{l1.P2.X, l1.P2.Y}
+	l2_p1 := mymath.Point{l2.P1.X, l2.P1.Y}
+	l2_p2 := mymath.Point{l2.P2.X, l2.P2.Y}
+	gap := l1.Gap
+	if l2.Gap > gap {
+		gap = l2.Gap
+	}
+	r := l1.Radius + l2.Radius + gap
+	if (l1.Taper != 0.0) || (l2.Taper != 0.0) {
+		c1, c2 := mymath.Closest_points_lines_2d(&l1_p1, &l1_p2, &l2_p1, &l2_p2)
+		r += l1.Taper*line_param(l1, c1) + l2.Taper*line_param(l2, c2)
+	}
+	return mymath.Contact_thick_lines_2d(&l1_p1, &l1_p2, &l2_p1, &l2_p2, r)
+}
+
+//parameter along a line of a point on it
+func line_param(l *Line, pp *mymath.Point) float32 {
+	p := *pp
+	dx, dy := l.P2.X-l.P1.X, l.P2.Y-l.P1.Y
+	d := dx*dx + dy*dy
+	if d == 0.0 {
+		return 0.0
+	}
+	return ((p[0]-l.P1.X)*dx + (p[1]-l.P1.Y)*dy) / d
+}
+
+func collide_lines64(l1, l2 *Line64) bool {
+	gap := math.Max(l1.Gap, l2.Gap)
+	if (l1.Taper == 0.0) && (l2.Taper == 0.0) {
+		return mymath.Collide_thick_lines_vec64(l1.P1, l1.P2, l2.P1, l2.P2, l1.Radius+l2.Radius+gap)
+	}
+	return mymath.Collide_tapered_lines_vec64(l1.P1, l1.P2, l1.Radius+gap, l1.Radius+l1.Taper+gap,
+		l2.P1, l2.P2, l2.Radius, l2.Radius+l2.Taper)
+}
+
+//exact for uniform lines, tapered lines fall back to float64 in integer units
+func collide_lines_i64(l1, l2 *Line_i64) bool {
+	gap := l1.Gap
+	if l2.Gap > gap {
+		gap = l2.Gap
+	}
+	if (l1.Taper == 0) && (l2.Taper == 0) {
+		return mymath.Collide_thick_lines_i64(l1.P1, l1.P2, l2.P1, l2.P2, l1.Radius+l2.Radius+gap)
+	}
+	v := func(p mymath.Ipoint) mymath.Vec64 { return mymath.Vec64{float64(p.X), float64(p.Y)} }
+	return mymath.Collide_tapered_lines_vec64(v(l1.P1), v(l1.P2), float64(l1.Radius+gap), float64(l1.Radius+l1.Taper+gap),
+		v(l2.P1), v(l2.P2), float64(l2.Radius), float64(l2.Radius+l2.Taper))
+}
+
+//contact between float64 lines, tapered radii are taken at the closest points
+func contact_lines64(l1, l2 *Line64) *mymath.Contact {
+	gap := math.Max(l1.Gap, l2.Gap)
+	r := l1.Radius + l2.Radius + gap
+	c := mymath.Contact_thick_lines_vec64(l1.P1, l1.P2, l2.P1, l2.P2, r)
+	if (l1.Taper != 0.0) || (l2.Taper != 0.0) {
+		r += l1.Taper*line_param64(l1, c.P1) + l2.Taper*line_param64(l2, c.P2)
+		c = mymath.Contact_thick_lines_vec64(l1.P1, l1.P2, l2.P1, l2.P2, r)
+	}
+	return c
+}
+
+func line_param64(l *Line64, pp *mymath.Point) float64 {
+	p := *pp
+	dx, dy := l.P2.X-l.P1.X, l.P2.Y-l.P1.Y
+	d := dx*dx + dy*dy
+	if d == 0.0 {
+		return 0.0
+	}
+	return ((float64(p[0])-l.P1.X)*dx + (float64(p[1])-l.P1.Y)*dy) / d
+}
+
+//surface distance from the query line q to l, and the closest point on l's centre
+func nearest_lines64(q, l *Line64, id int) *Nearest {
+	c := mymath.Contact_thick_lines_vec64(q.P1, q.P2, l.P1, l.P2, 0.0)
+	r := q.Radius + q.Taper*line_param64(q, c.P1) + l.Radius + l.Taper*line_param64(l, c.P2)
+	return &Nearest{id, mymath.Distance_2d(c.P1, c.P2) - float32(r), c.P2}
+}
+
+func min_int(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max_int(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func region_edges_hit(edges [][2]mymath.Vec64, l *Line64) bool {
+	r := l.Radius + l.Gap
+	for _, e := range edges {
+		if mymath.Collide_tapered_lines_vec64(e[0], e[1], 0.0, 0.0, l.P1, l.P2, r, r+l.Taper) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+//package name
+package layer
+
+//package imports
+import (
+	"../mymath"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+/////////////////////////
+//private structure/types
+/////////////////////////
+
+//a coordinate mode under test
+type test_mode struct {
+	name string
+	opts []Option
+}
+
+var test_modes = []test_mode{
+	{"float32", nil},
+	{"float64", []Option{Float64_coords()}},
+	{"int64", []Option{Int64_coords(1000.0)}},
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func test_mode_layer(mode test_mode) *Layer {
+	return Newlayer(test_cols, test_rows, test_scale, test_scale, mode.opts...)
+}
+
+func test_point(x, y float32) *Line {
+	return &Line{&Point{x, y}, &Point{x, y}, 0.01, 0.0, 0.0}
+}
+
+///////
+//tests
+///////
+
+//radii of the wrong length are fitted to the path, and a sub given the same
+//radii takes out what the add put in
+func TestVariable_path_radii(t *testing.T) {
+	path := &mymath.Points{&mymath.Point{100.0, 100.0}, &mymath.Point{200.0, 100.0}, &mymath.Point{200.0, 200.0}}
+	origin := &mymath.Point{0.0, 0.0}
+	for _, mode := range test_modes {
+		for _, radii := range [][]float32{{3.0}, {3.0, 3.0, 3.0, 3.0}, {}} {
+			l := test_mode_layer(mode)
+			l.Add_variable_path(origin, path, radii, 0.0, 1)
+			want := 1
+			if len(radii) == 0 {
+				want = -1
+			}
+			if hit := l.Hit_Line(test_point(202.0, 150.0)); hit != want {
+				t.Errorf("%s radii %v: hit %d beside the second line, want %d", mode.name, radii, hit, want)
+			}
+			l.Sub_variable_path(origin, path, radii, 0.0, 1)
+			if hit := l.Hit_Line(test_point(200.0, 150.0)); hit != -1 {
+				t.Errorf("%s radii %v: hit %d after the sub", mode.name, radii, hit)
+			}
+		}
+	}
+}
+
+//contacts come back in coordinate units whatever the mode
+func TestHit_Line_contacts(t *testing.T) {
+	for _, mode := range test_modes {
+		l := test_mode_layer(mode)
+		l.Add_Line(&Line{&Point{100.0, 100.0}, &Point{200.0, 100.0}, 2.0, 0.0, 0.0}, 1)
+		l.Add_Line(&Line{&Point{300.0, 100.0}, &Point{400.0, 100.0}, 2.0, 0.0, 0.0}, 2)
+		hits := l.Hit_Line_contacts(&Line{&Point{150.0, 103.0}, &Point{150.0, 110.0}, 2.0, 0.5, 0.0})
+		if len(hits) != 1 || hits[0].Id != 1 {
+			t.Errorf("%s: hits %v, want id 1", mode.name, hits)
+			continue
+		}
+		c := hits[0].Contact
+		if (math.Abs(float64(c.Depth)-1.5) > 0.001) || (mymath.Distance_2d(c.Mtv, &mymath.Point{0.0, 1.5}) > 0.001) {
+			t.Errorf("%s: depth %v mtv %v, want 1.5 and (0, 1.5)", mode.name, c.Depth, c.Mtv)
+		}
+	}
+}
+
+//gaps count as part of a line, and contained means the whole outline is inside
+func TestHit_Rect_Polygon(t *testing.T) {
+	lines := []struct {
+		line *Line
+		id   int
+	}{
+		{&Line{&Point{120.0, 150.0}, &Point{180.0, 150.0}, 2.0, 0.0, 0.0}, 1},
+		{&Line{&Point{150.0, 140.0}, &Point{250.0, 140.0}, 2.0, 0.0, 0.0}, 2},
+		{&Line{&Point{150.0, 202.5}, &Point{180.0, 202.5}, 3.0, 0.0, 0.0}, 3},
+		{&Line{&Point{150.0, 204.0}, &Point{180.0, 204.0}, 3.0, 2.0, 0.0}, 4},
+		{&Line{&Point{150.0, 220.0}, &Point{180.0, 220.0}, 2.0, 2.0, 0.0}, 5},
+		{&Line{&Point{120.0, 196.0}, &Point{180.0, 196.0}, 2.0, 3.0, 0.0}, 6},
+		{&Line{&Point{120.0, 120.0}, &Point{130.0, 130.0}, 2.0, 0.0, 0.0}, 7},
+		{&Line{&Point{130.0, 130.0}, &Point{130.0, 300.0}, 2.0, 0.0, 0.0}, 7},
+		//across the notch of the u, both ends inside its arms
+		{&Line{&Point{110.0, 180.0}, &Point{190.0, 180.0}, 1.0, 0.0, 0.0}, 8},
+		{&Line{&Point{110.0, 115.0}, &Point{190.0, 115.0}, 1.0, 0.0, 0.0}, 9},
+	}
+	u_shape := &mymath.Points{&mymath.Point{100.0, 100.0}, &mymath.Point{200.0, 100.0}, &mymath.Point{200.0, 200.0},
+		&mymath.Point{170.0, 200.0}, &mymath.Point{170.0, 130.0}, &mymath.Point{130.0, 130.0},
+		&mymath.Point{130.0, 200.0}, &mymath.Point{100.0, 200.0}}
+	for _, mode := range test_modes {
+		l := test_mode_layer(mode)
+		for _, line := range lines {
+			l.Add_Line(line.line, line.id)
+		}
+		tests := []struct {
+			name string
+			hits []int
+			want []int
+		}{
+			{"rect touching", l.Hit_Rect(&mymath.Point{200.0, 200.0}, &mymath.Point{100.0, 100.0}, false),
+				[]int{1, 2, 3, 4, 6, 7, 8, 9}},
+			{"rect contained", l.Hit_Rect(&mymath.Point{100.0, 100.0}, &mymath.Point{200.0, 200.0}, true),
+				[]int{1, 8, 9}},
+			{"u touching", l.Hit_Polygon(u_shape, false), []int{1, 2, 3, 4, 6, 7, 8, 9}},
+			{"u contained", l.Hit_Polygon(u_shape, true), []int{9}},
+		}
+		for _, test := range tests {
+			sort.Ints(test.hits)
+			if fmt.Sprint(test.hits) != fmt.Sprint(test.want) {
+				t.Errorf("%s %s: hits %v, want %v", mode.name, test.name, test.hits, test.want)
+			}
+		}
+	}
+}
+
+func TestInt64_coords_units(t *testing.T) {
+	for _, units := range []float64{0.0, -1000.0, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("units %v accepted", units)
+				}
+			}()
+			Int64_coords(units)
+		}()
+	}
+	//units under one are fine, coordinates just round more coarsely
+	l := Newlayer(test_cols, test_rows, test_scale, test_scale, Int64_coords(0.5))
+	l.Add_Line(&Line{&Point{100.0, 100.0}, &Point{200.0, 100.0}, 2.0, 0.0, 0.0}, 1)
+	if hit := l.Hit_Line(test_point(150.0, 101.0)); hit != 1 {
+		t.Errorf("hit %d with half units, want 1", hit)
+	}
+}
+
+//integer lines in nanometres are tested exactly, down to a single unit apart,
+//which float32 coordinates of the same size cannot tell apart
+func TestInt64_exact(t *testing.T) {
+	const units = 1000000.0
+	l := test_mode_layer(test_mode{"int64", []Option{Int64_coords(units)}})
+	p := func(x, y int64) mymath.Ipoint { return mymath.Ipoint{x, y} }
+	l.Add_path_i64(p(0, 0), []mymath.Ipoint{p(100000000, 100000000), p(200000000, 100000000), p(200000000, 200000000)}, 2000000, 0, 1)
+	tests := []struct {
+		name string
+		q    *Line_i64
+		want int
+	}{
+		{"touching the side", &Line_i64{p(150000001, 102000000), p(150000001, 102000000), 0, 0, 0}, 1},
+		{"a unit off the side", &Line_i64{p(150000001, 102000001), p(150000001, 102000001), 0, 0, 0}, -1},
+		{"touching the corner", &Line_i64{p(200000000, 98000000), p(200000000, 98000000), 0, 0, 0}, 1},
+		{"a unit off the end", &Line_i64{p(200000000, 202000001), p(200000000, 202000001), 0, 0, 0}, -1},
+		{"line reaching", &Line_i64{p(203000000, 150000000), p(300000000, 150000000), 1000000, 0, 0}, 1},
+		{"line a unit short", &Line_i64{p(203000001, 150000000), p(300000000, 150000000), 1000000, 0, 0}, -1},
+	}
+	for _, test := range tests {
+		if hit := l.Hit_Line_i64(test.q); hit != test.want {
+			t.Errorf("%s: hit %d, want %d", test.name, hit, test.want)
+		}
+	}
+	l.Sub_path_i64(p(0, 0), []mymath.Ipoint{p(100000000, 100000000), p(200000000, 100000000), p(200000000, 200000000)}, 2000000, 0, 1)
+	if hit := l.Hit_Line_i64(tests[0].q); hit != -1 {
+		t.Errorf("hit %d after the sub", hit)
+	}
+}
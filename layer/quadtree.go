@@ -0,0 +1,223 @@
+//package name
+package layer
+
+/////////////////////////
+//private structure/types
+/////////////////////////
+
+//items per node before it splits
+const quad_items = 8
+
+type quad_item struct {
+	item interface{}
+	box  Box
+}
+
+//an item lives in the smallest node that holds all of its box,
+//children are made as they are needed
+type quad_node struct {
+	box      Box
+	items    []*quad_item
+	children [4]*quad_node
+	split    bool
+}
+
+//the root doubles in size towards anything added outside it
+type quadtree struct {
+	root     *quad_node
+	min_size float64
+	count    int
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func newquadtree(box *Box) *quadtree {
+	b := *box
+	if (b.Maxx <= b.Minx) || (b.Maxy <= b.Miny) {
+		b = Box{b.Minx, b.Miny, b.Minx + 1.0, b.Miny + 1.0}
+	}
+	q := quadtree{}
+	q.root = &quad_node{box: b}
+	q.min_size = (b.Maxx - b.Minx) / 65536.0
+	return &q
+}
+
+/////////////////
+//Index interface
+/////////////////
+
+func (self *quadtree) Add(item interface{}, box *Box) {
+	if !box_finite(box) {
+		return
+	}
+	self.count++
+	for !box_contains(&self.root.box, box) {
+		self.grow(box)
+	}
+	self.insert(self.root, &quad_item{item, *box})
+}
+
+func (self *quadtree) Sub(item interface{}, box *Box) {
+	if box_finite(box) && self.sub(self.root, item, box) {
+		self.count--
+	}
+}
+
+func (self *quadtree) Hit(box *Box, hit func(item interface{}) bool) interface{} {
+	return index_hit(self, box, hit)
+}
+
+func (self *quadtree) Query(box *Box, f func(item interface{}) bool) {
+	if box_finite(box) {
+		self.query(self.root, box, f)
+	}
+}
+
+func (self *quadtree) Load(items []interface{}, boxes []*Box) {
+	for i, item := range items {
+		self.Add(item, boxes[i])
+	}
+}
+
+//the root's box, which is loose
+func (self *quadtree) Bounds() *Box {
+	if self.count == 0 {
+		return nil
+	}
+	b := self.root.box
+	return &b
+}
+
+/////////////////
+//private methods
+/////////////////
+
+//double the root towards the box, the old root becomes one of its quadrants
+func (self *quadtree) grow(box *Box) {
+	old := self.root
+	b := old.box
+	w, h := b.Maxx-b.Minx, b.Maxy-b.Miny
+	i := 0
+	if box.Minx < b.Minx {
+		b.Minx -= w
+	} else {
+		b.Maxx += w
+		i |= 1
+	}
+	if box.Miny < b.Miny {
+		b.Miny -= h
+	} else {
+		b.Maxy += h
+		i |= 2
+	}
+	self.root = &quad_node{box: b, split: true}
+	self.root.children[i] = old
+}
+
+func (self *quadtree) insert(node *quad_node, qi *quad_item) {
+	for node.split {
+		i := node.quadrant(&qi.box)
+		if i == -1 {
+			break
+		}
+		node = node.child(i)
+	}
+	node.items = append(node.items, qi)
+	if node.split || (len(node.items) <= quad_items) || (node.box.Maxx-node.box.Minx <= self.min_size) {
+		return
+	}
+	//split, moving down every item that fits a quadrant
+	node.split = true
+	items := node.items
+	node.items = nil
+	for _, qi := range items {
+		if i := node.quadrant(&qi.box); i != -1 {
+			c := node.child(i)
+			c.items = append(c.items, qi)
+		} else {
+			node.items = append(node.items, qi)
+		}
+	}
+}
+
+//items on a quadrant's edge could be in either, so look in every child holding the box
+func (self *quadtree) sub(node *quad_node, item interface{}, box *Box) bool {
+	for i, qi := range node.items {
+		if qi.item == item {
+			node.items = append(node.items[:i], node.items[i+1:]...)
+			return true
+		}
+	}
+	for _, c := range node.children {
+		if (c != nil) && box_contains(&c.box, box) && self.sub(c, item, box) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *quadtree) query(node *quad_node, box *Box, f func(item interface{}) bool) bool {
+	if (node == nil) || !box_overlaps(&node.box, box) {
+		return true
+	}
+	for _, qi := range node.items {
+		if box_overlaps(&qi.box, box) {
+			if !f(qi.item) {
+				return false
+			}
+		}
+	}
+	for _, c := range node.children {
+		if !self.query(c, box, f) {
+			return false
+		}
+	}
+	return true
+}
+
+//index of the quadrant that holds all of the box, -1 if none does,
+//bit 0 set for the low x half and bit 1 for the low y half
+func (self *quad_node) quadrant(box *Box) int {
+	mx := (self.box.Minx + self.box.Maxx) * 0.5
+	my := (self.box.Miny + self.box.Maxy) * 0.5
+	i := 0
+	switch {
+	case box.Maxx <= mx:
+		i |= 1
+	case box.Minx < mx:
+		return -1
+	}
+	switch {
+	case box.Maxy <= my:
+		i |= 2
+	case box.Miny < my:
+		return -1
+	}
+	//a grown root's old root may not split it exactly
+	if (self.children[i] != nil) && !box_contains(&self.children[i].box, box) {
+		return -1
+	}
+	return i
+}
+
+func (self *quad_node) child(i int) *quad_node {
+	if self.children[i] == nil {
+		b := self.box
+		mx := (b.Minx + b.Maxx) * 0.5
+		my := (b.Miny + b.Maxy) * 0.5
+		if (i & 1) != 0 {
+			b.Maxx = mx
+		} else {
+			b.Minx = mx
+		}
+		if (i & 2) != 0 {
+			b.Maxy = my
+		} else {
+			b.Miny = my
+		}
+		self.children[i] = &quad_node{box: b}
+	}
+	return self.children[i]
+}
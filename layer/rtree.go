@@ -0,0 +1,317 @@
+//package name
+package layer
+
+//package imports
+import (
+	"math"
+	"sort"
+)
+
+/////////////////////////
+//private structure/types
+/////////////////////////
+
+//entries per node
+const (
+	rtree_max = 16
+	rtree_min = 4
+)
+
+//leaf entries hold an item, the others a child node
+type rtree_entry struct {
+	box   Box
+	item  interface{}
+	child *rtree_node
+}
+
+type rtree_node struct {
+	box     Box
+	leaf    bool
+	entries []*rtree_entry
+}
+
+//guttman's r-tree with quadratic splits, all leaves at the same depth
+type rtree struct {
+	root  *rtree_node
+	count int
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func newrtree() *rtree {
+	return &rtree{&rtree_node{leaf: true}, 0}
+}
+
+/////////////////
+//Index interface
+/////////////////
+
+func (self *rtree) Add(item interface{}, box *Box) {
+	if !box_finite(box) {
+		return
+	}
+	self.count++
+	self.insert(&rtree_entry{box: *box, item: item})
+}
+
+func (self *rtree) Sub(item interface{}, box *Box) {
+	if !box_finite(box) {
+		return
+	}
+	orphans := []*rtree_entry{}
+	found, _ := self.remove(self.root, item, box, &orphans)
+	if !found {
+		return
+	}
+	self.count--
+	for !self.root.leaf && (len(self.root.entries) == 1) {
+		self.root = self.root.entries[0].child
+	}
+	for _, e := range orphans {
+		self.insert(e)
+	}
+}
+
+func (self *rtree) Hit(box *Box, hit func(item interface{}) bool) interface{} {
+	return index_hit(self, box, hit)
+}
+
+func (self *rtree) Query(box *Box, f func(item interface{}) bool) {
+	if (self.count != 0) && box_finite(box) {
+		self.query(self.root, box, f)
+	}
+}
+
+//sort tile recursive bulk load, rebuilding the tree with what it already holds
+func (self *rtree) Load(items []interface{}, boxes []*Box) {
+	entries := self.leaf_entries(self.root, nil)
+	for i, item := range items {
+		if box_finite(boxes[i]) {
+			entries = append(entries, &rtree_entry{box: *boxes[i], item: item})
+		}
+	}
+	self.count = len(entries)
+	if len(entries) == 0 {
+		self.root = &rtree_node{leaf: true}
+		return
+	}
+	leaf := true
+	for {
+		nodes := str_pack(entries, leaf)
+		if len(nodes) == 1 {
+			self.root = nodes[0]
+			return
+		}
+		entries = entries[:0:0]
+		for _, n := range nodes {
+			entries = append(entries, &rtree_entry{box: n.box, child: n})
+		}
+		leaf = false
+	}
+}
+
+func (self *rtree) Bounds() *Box {
+	if self.count == 0 {
+		return nil
+	}
+	b := self.root.box
+	return &b
+}
+
+/////////////////
+//private methods
+/////////////////
+
+func (self *rtree) insert(e *rtree_entry) {
+	if split := self.insert_node(self.root, e); split != nil {
+		old := self.root
+		self.root = &rtree_node{leaf: false, entries: []*rtree_entry{{box: old.box, child: old}, {box: split.box, child: split}}}
+		self.root.fit()
+	}
+}
+
+//insert into the subtree, returning the new sibling if the node had to split
+func (self *rtree) insert_node(n *rtree_node, e *rtree_entry) *rtree_node {
+	if n.leaf {
+		n.entries = append(n.entries, e)
+	} else {
+		//the child needing least enlargement, then the smallest
+		var best *rtree_entry
+		best_grow, best_area := math.Inf(1), math.Inf(1)
+		for _, c := range n.entries {
+			area := box_area(&c.box)
+			grow := box_area(box_union(&c.box, &e.box)) - area
+			if (grow < best_grow) || ((grow == best_grow) && (area < best_area)) {
+				best, best_grow, best_area = c, grow, area
+			}
+		}
+		if split := self.insert_node(best.child, e); split != nil {
+			n.entries = append(n.entries, &rtree_entry{box: split.box, child: split})
+		}
+		best.box = best.child.box
+	}
+	if len(n.entries) > rtree_max {
+		return n.split()
+	}
+	n.fit()
+	return nil
+}
+
+//remove an item, gathering the leaf entries of nodes left too small to be reinserted.
+//returns whether it was found and whether n is now too small
+func (self *rtree) remove(n *rtree_node, item interface{}, box *Box, orphans *[]*rtree_entry) (bool, bool) {
+	if n.leaf {
+		for i, e := range n.entries {
+			if e.item == item {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				n.fit()
+				return true, len(n.entries) < rtree_min
+			}
+		}
+		return false, false
+	}
+	for i, c := range n.entries {
+		if !box_contains(&c.box, box) {
+			continue
+		}
+		found, under := self.remove(c.child, item, box, orphans)
+		if !found {
+			continue
+		}
+		if under {
+			*orphans = self.leaf_entries(c.child, *orphans)
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		} else {
+			c.box = c.child.box
+		}
+		n.fit()
+		return true, len(n.entries) < rtree_min
+	}
+	return false, false
+}
+
+func (self *rtree) query(n *rtree_node, box *Box, f func(item interface{}) bool) bool {
+	for _, e := range n.entries {
+		if !box_overlaps(&e.box, box) {
+			continue
+		}
+		if n.leaf {
+			if !f(e.item) {
+				return false
+			}
+		} else if !self.query(e.child, box, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *rtree) leaf_entries(n *rtree_node, entries []*rtree_entry) []*rtree_entry {
+	if n.leaf {
+		return append(entries, n.entries...)
+	}
+	for _, e := range n.entries {
+		entries = self.leaf_entries(e.child, entries)
+	}
+	return entries
+}
+
+//box round all the entries
+func (self *rtree_node) fit() {
+	if len(self.entries) == 0 {
+		self.box = Box{}
+		return
+	}
+	b := self.entries[0].box
+	for _, e := range self.entries[1:] {
+		b = *box_union(&b, &e.box)
+	}
+	self.box = b
+}
+
+//quadratic split, seeded with the pair that would waste the most area together,
+//the node keeps one group and the other is returned as a new sibling
+func (self *rtree_node) split() *rtree_node {
+	entries := self.entries
+	s1, s2, worst := 0, 1, math.Inf(-1)
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			d := box_area(box_union(&entries[i].box, &entries[j].box)) - box_area(&entries[i].box) - box_area(&entries[j].box)
+			if d > worst {
+				s1, s2, worst = i, j, d
+			}
+		}
+	}
+	g1 := &rtree_node{leaf: self.leaf, entries: []*rtree_entry{entries[s1]}}
+	g2 := &rtree_node{leaf: self.leaf, entries: []*rtree_entry{entries[s2]}}
+	g1.fit()
+	g2.fit()
+	rest := []*rtree_entry{}
+	for i, e := range entries {
+		if (i != s1) && (i != s2) {
+			rest = append(rest, e)
+		}
+	}
+	for len(rest) != 0 {
+		//a group short of the minimum takes all that are left
+		if len(g1.entries)+len(rest) == rtree_min {
+			g1.entries = append(g1.entries, rest...)
+			break
+		}
+		if len(g2.entries)+len(rest) == rtree_min {
+			g2.entries = append(g2.entries, rest...)
+			break
+		}
+		//place the entry with the strongest preference next
+		pick, diff := 0, math.Inf(-1)
+		for i, e := range rest {
+			d1 := box_area(box_union(&g1.box, &e.box)) - box_area(&g1.box)
+			d2 := box_area(box_union(&g2.box, &e.box)) - box_area(&g2.box)
+			if d := math.Abs(d1 - d2); d > diff {
+				pick, diff = i, d
+			}
+		}
+		e := rest[pick]
+		rest = append(rest[:pick], rest[pick+1:]...)
+		d1 := box_area(box_union(&g1.box, &e.box)) - box_area(&g1.box)
+		d2 := box_area(box_union(&g2.box, &e.box)) - box_area(&g2.box)
+		if (d1 < d2) || ((d1 == d2) && (len(g1.entries) <= len(g2.entries))) {
+			g1.entries = append(g1.entries, e)
+			g1.fit()
+		} else {
+			g2.entries = append(g2.entries, e)
+			g2.fit()
+		}
+	}
+	g1.fit()
+	g2.fit()
+	self.entries = g1.entries
+	self.box = g1.box
+	return g2
+}
+
+//pack entries into nodes, sorting into vertical slices by centre x and
+//then each slice into runs of full nodes by centre y
+func str_pack(entries []*rtree_entry, leaf bool) []*rtree_node {
+	nodes := []*rtree_node{}
+	n := len(entries)
+	slices := int(math.Ceil(math.Sqrt(math.Ceil(float64(n) / rtree_max))))
+	per_slice := slices * rtree_max
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].box.Minx+entries[i].box.Maxx < entries[j].box.Minx+entries[j].box.Maxx
+	})
+	for s := 0; s < n; s += per_slice {
+		slice := entries[s:min_int(s+per_slice, n)]
+		sort.Slice(slice, func(i, j int) bool {
+			return slice[i].box.Miny+slice[i].box.Maxy < slice[j].box.Miny+slice[j].box.Maxy
+		})
+		for i := 0; i < len(slice); i += rtree_max {
+			node := &rtree_node{leaf: leaf, entries: append([]*rtree_entry{}, slice[i:min_int(i+rtree_max, len(slice))]...)}
+			node.fit()
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
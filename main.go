@@ -21,15 +21,22 @@ const (
 )
 
 type shape struct {
-	offset   *mymath.Point
-	red      float32
-	blue     float32
-	green    float32
-	alpha    float32
-	strip_id int
-	path_id  int
-	radius   float32
-	gap      float32
+	offset    *mymath.Point
+	transform *mymath.Matrix
+	red       float32
+	blue      float32
+	green     float32
+	alpha     float32
+	strip_id  int
+	path_id   int
+	radius    float32
+	gap       float32
+}
+
+//shape transform followed by its offset
+func shape_matrix(s *shape) *mymath.Matrix {
+	offset := *s.offset
+	return mymath.Mul_matrix(mymath.Translate_matrix(offset[0], offset[1]), s.transform)
 }
 
 //load shader progs
@@ -104,28 +111,28 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 }
 
 //draw a line strip polygon
-func draw_polygon(offsetp *mymath.Point, datap *mymath.Points) {
-	data, offset := *datap, *offsetp
+func draw_polygon(matrixp *mymath.Matrix, datap *mymath.Points) {
+	data := *mymath.Transform_points(matrixp, datap)
 	vertex_buffer_data := make([]float32, len(data)*2, len(data)*2)
 	for i := 0; i < len(data); i++ {
 		pp := data[i]
 		p := *pp
-		vertex_buffer_data[i*2] = p[0] + offset[0]
-		vertex_buffer_data[i*2+1] = p[1] + offset[1]
+		vertex_buffer_data[i*2] = p[0]
+		vertex_buffer_data[i*2+1] = p[1]
 	}
 	gl.BufferData(gl.ARRAY_BUFFER, len(vertex_buffer_data)*4, gl.Ptr(vertex_buffer_data), gl.STATIC_DRAW)
 	gl.DrawArrays(gl.LINE_STRIP, 0, int32(len(vertex_buffer_data)/2))
 }
 
 //draw a triangle strip polygon
-func draw_filled_polygon(offsetp *mymath.Point, datap *mymath.Points) {
-	data, offset := *datap, *offsetp
+func draw_filled_polygon(matrixp *mymath.Matrix, datap *mymath.Points) {
+	data := *mymath.Transform_points(matrixp, datap)
 	vertex_buffer_data := make([]float32, len(data)*2, len(data)*2)
 	for i := 0; i < len(data); i++ {
 		pp := data[i]
 		p := *pp
-		vertex_buffer_data[i*2] = p[0] + offset[0]
-		vertex_buffer_data[i*2+1] = p[1] + offset[1]
+		vertex_buffer_data[i*2] = p[0]
+		vertex_buffer_data[i*2+1] = p[1]
 	}
 	gl.BufferData(gl.ARRAY_BUFFER, len(vertex_buffer_data)*4, gl.Ptr(vertex_buffer_data), gl.STATIC_DRAW)
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(len(vertex_buffer_data)/2))
@@ -231,17 +238,17 @@ func main() {
 
 	//add instances to shape map
 	shape_map := map[int]*shape{}
-	shape_map[0] = &shape{&mymath.Point{25.0, 25.0}, 1.0, 1.0, 1.0, 1.0, bez_strip_id, bez_path_id, 15, 0}
-	shape_map[1] = &shape{&mymath.Point{200.0, 300.0}, 1.0, 0.0, 0.0, 1.0, circle_strip_id, circle_path_id, 25, 0}
-	shape_map[2] = &shape{&mymath.Point{250.0, 550.0}, 0.0, 1.0, 0.0, 1.0, circle_strip_id, circle_path_id, 25, 0}
-	shape_map[3] = &shape{&mymath.Point{600.0, 300.0}, 0.0, 0.0, 1.0, 1.0, stroke_strip_id, stroke_path_id, 10, 0}
-	shape_map[4] = &shape{&mymath.Point{600.0, 500.0}, 1.0, 1.0, 0.0, 1.0, stroke_strip_id, stroke_path_id, 10, 0}
-	shape_map[5] = &shape{&mymath.Point{800.0, 100.0}, 0.0, 1.0, 1.0, 0.75, circle_strip_id, circle_path_id, 25, 0}
-	shape_map[6] = &shape{&mymath.Point{350.0, 250.0}, 1.0, 0.0, 1.0, 0.75, bez_strip_id, bez_path_id, 15, 0}
+	shape_map[0] = &shape{&mymath.Point{25.0, 25.0}, mymath.Identity_matrix(), 1.0, 1.0, 1.0, 1.0, bez_strip_id, bez_path_id, 15, 0}
+	shape_map[1] = &shape{&mymath.Point{200.0, 300.0}, mymath.Identity_matrix(), 1.0, 0.0, 0.0, 1.0, circle_strip_id, circle_path_id, 25, 0}
+	shape_map[2] = &shape{&mymath.Point{250.0, 550.0}, mymath.Identity_matrix(), 0.0, 1.0, 0.0, 1.0, circle_strip_id, circle_path_id, 25, 0}
+	shape_map[3] = &shape{&mymath.Point{600.0, 300.0}, mymath.Identity_matrix(), 0.0, 0.0, 1.0, 1.0, stroke_strip_id, stroke_path_id, 10, 0}
+	shape_map[4] = &shape{&mymath.Point{600.0, 500.0}, mymath.Identity_matrix(), 1.0, 1.0, 0.0, 1.0, stroke_strip_id, stroke_path_id, 10, 0}
+	shape_map[5] = &shape{&mymath.Point{800.0, 100.0}, mymath.Identity_matrix(), 0.0, 1.0, 1.0, 0.75, circle_strip_id, circle_path_id, 25, 0}
+	shape_map[6] = &shape{&mymath.Point{350.0, 250.0}, mymath.Identity_matrix(), 1.0, 0.0, 1.0, 0.75, bez_strip_id, bez_path_id, 15, 0}
 
 	//add shape paths to spacial cache
 	for shape_id, shape := range shape_map {
-		dlist.Add_collision_path(shape.offset, shape.path_id, shape.radius, shape.gap, shape_id)
+		dlist.Add_transformed_collision_path(shape_matrix(shape), shape.path_id, shape.radius, shape.gap, shape_id)
 	}
 
 	mouse_shape_id := 0
@@ -269,9 +276,9 @@ func main() {
 			}
 			if mouse_shape_id != -1 {
 				shape := shape_map[mouse_shape_id]
-				dlist.Sub_collision_path(shape.offset, shape.path_id, shape.radius, shape.gap, mouse_shape_id)
+				dlist.Sub_transformed_collision_path(shape_matrix(shape), shape.path_id, shape.radius, shape.gap, mouse_shape_id)
 				shape.offset = &mymath.Point{float32(xpos) - drag_offset_x, float32(ypos) - drag_offset_y}
-				dlist.Add_collision_path(shape.offset, shape.path_id, shape.radius, shape.gap, mouse_shape_id)
+				dlist.Add_transformed_collision_path(shape_matrix(shape), shape.path_id, shape.radius, shape.gap, mouse_shape_id)
 			}
 		} else {
 			mouse_shape_id = -1
@@ -289,9 +296,9 @@ func main() {
 		for _, id := range keys {
 			shape := shape_map[id]
 			gl.Uniform4f(vert_color_id, shape.red, shape.green, shape.blue, shape.alpha)
-			draw_filled_polygon(shape.offset, dlist.Get_strip(shape.strip_id))
+			draw_filled_polygon(shape_matrix(shape), dlist.Get_strip(shape.strip_id))
 			gl.Uniform4f(vert_color_id, 0.0, 0.0, 0.0, 1.0)
-			draw_polygon(shape.offset, dlist.Get_path(shape.path_id))
+			draw_polygon(shape_matrix(shape), dlist.Get_path(shape.path_id))
 		}
 
 		//show window just drawn
@@ -0,0 +1,100 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+//////////////////
+//public functions
+//////////////////
+
+//total length of a path
+func Path_length(pathp *Points) float32 {
+	path := *pathp
+	length := float32(0.0)
+	for i := 1; i < len(path); i++ {
+		length += Distance_2d(path[i-1], path[i])
+	}
+	return length
+}
+
+//point a distance along a path, distances are clamped to the path
+func Path_point_at(pathp *Points, distance float32) *Point {
+	path := *pathp
+	if len(path) == 0 {
+		return &Point{0.0, 0.0}
+	}
+	i, t := path_locate(path, distance)
+	if i == -1 {
+		return &Point{(*path[0])[0], (*path[0])[1]}
+	}
+	return Add_2d(path[i], Scale_2d(Sub_2d(path[i+1], path[i]), t))
+}
+
+//unit direction of a path a distance along it, at a corner the
+//direction of the segment leaving the corner is taken
+func Path_tangent_at(pathp *Points, distance float32) *Point {
+	path := *pathp
+	i, _ := path_locate(path, distance)
+	if i == -1 {
+		return &Point{1.0, 0.0}
+	}
+	return Norm_2d(Sub_2d(path[i+1], path[i]))
+}
+
+//unit normal of a path a distance along it, to the right of the direction of travel
+func Path_normal_at(pathp *Points, distance float32) *Point {
+	return Perp_2d(Path_tangent_at(pathp, distance))
+}
+
+//split a path in two at a distance along it, the split point ends the
+//first path and starts the second
+func Split_path_at(pathp *Points, distance float32) (*Points, *Points) {
+	path := *pathp
+	i, t := path_locate(path, distance)
+	if i == -1 {
+		first, second := append(Points{}, path...), append(Points{}, path...)
+		return &first, &second
+	}
+	p := Add_2d(path[i], Scale_2d(Sub_2d(path[i+1], path[i]), t))
+	first := append(Points{}, path[:i+1]...)
+	if (len(first) == 1) || !Equal_2d(first[len(first)-1], p) {
+		first = append(first, p)
+	}
+	second := Points{p}
+	for _, pp := range path[i+1:] {
+		if !Equal_2d(second[len(second)-1], pp) {
+			second = append(second, pp)
+		}
+	}
+	if len(second) == 1 {
+		second = append(second, p)
+	}
+	return &first, &second
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//find the segment a distance along a path and the parameter along it,
+//zero length segments are never chosen, -1 if the path has no length
+func path_locate(path Points, distance float32) (int, float32) {
+	last := -1
+	for i := 1; i < len(path); i++ {
+		l := Distance_2d(path[i-1], path[i])
+		if l == 0.0 {
+			continue
+		}
+		last = i - 1
+		if distance < l {
+			return last, float32(math.Max(0.0, float64(distance/l)))
+		}
+		distance -= l
+	}
+	return last, 1.0
+}
@@ -0,0 +1,371 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"sort"
+)
+
+//////////////////////////
+//private structures/types
+//////////////////////////
+
+type clip_edge struct {
+	p1     point64
+	p2     point64
+	poly   int
+	splits []point64
+	minx   float64
+	miny   float64
+	maxx   float64
+	maxy   float64
+}
+
+type clip_key struct {
+	lo point64
+	hi point64
+}
+
+type clip_segment struct {
+	key     clip_key
+	winding [2]int
+}
+
+//////////////////
+//public functions
+//////////////////
+
+//boolean operation on two sets of closed contours, holes are just more contours.
+//op: 0 union, 1 intersection, 2 difference, 3 xor.
+//fill_rule: 0 even-odd, 1 non-zero, 2 positive, 3 negative, where counter
+//clockwise contours add one to the winding inside them.
+//returns closed contours, outlines wind counter clockwise and holes clockwise
+func Clip_polygons(subject, clip []*Points, op, fill_rule int) []*Points {
+	edges := []*clip_edge{}
+	edges = clip_add_contours(edges, subject, 0)
+	edges = clip_add_contours(edges, clip, 1)
+	clip_split_edges(edges)
+	segments := clip_merge_edges(edges)
+	boundary := clip_classify(segments, op, fill_rule)
+	return clip_link(boundary)
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func clip_add_contours(edges []*clip_edge, contours []*Points, poly int) []*clip_edge {
+	for _, contour := range contours {
+		c := tri_contour(contour)
+		if len(c) < 3 {
+			continue
+		}
+		for i := 0; i < len(c); i++ {
+			p1, p2 := c[i], c[(i+1)%len(c)]
+			e := &clip_edge{p1: p1, p2: p2, poly: poly}
+			e.minx, e.maxx = math.Min(p1.x, p2.x), math.Max(p1.x, p2.x)
+			e.miny, e.maxy = math.Min(p1.y, p2.y), math.Max(p1.y, p2.y)
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+func clip_split_edges(edges []*clip_edge) {
+	sorted := make([]*clip_edge, len(edges), len(edges))
+	copy(sorted, edges)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].minx < sorted[j].minx })
+	for i, e1 := range sorted {
+		for _, e2 := range sorted[i+1:] {
+			if e2.minx > e1.maxx {
+				break
+			}
+			if (e2.miny > e1.maxy) || (e2.maxy < e1.miny) {
+				continue
+			}
+			clip_intersect(e1, e2)
+		}
+	}
+}
+
+func clip_intersect(e1, e2 *clip_edge) {
+	count, p1, p2 := intersect64(e1.p1, e1.p2, e2.p1, e2.p2)
+	switch count {
+	case 1:
+		clip_add_split(e1, p1)
+		clip_add_split(e2, p1)
+	case 2:
+		//collinear overlap, each edge is split at the overlap ends
+		clip_add_split(e1, p1)
+		clip_add_split(e1, p2)
+		clip_add_split(e2, p1)
+		clip_add_split(e2, p2)
+	}
+}
+
+func clip_add_split(e *clip_edge, p point64) {
+	if (p != e.p1) && (p != e.p2) {
+		e.splits = append(e.splits, p)
+	}
+}
+
+//break edges at their split points and merge coincident pieces,
+//summing the winding each polygon contributes to the piece
+func clip_merge_edges(edges []*clip_edge) []*clip_segment {
+	segment_map := map[clip_key]*clip_segment{}
+	segments := []*clip_segment{}
+	for _, e := range edges {
+		rx, ry := e.p2.x-e.p1.x, e.p2.y-e.p1.y
+		points := append([]point64{e.p1}, e.splits...)
+		points = append(points, e.p2)
+		sort.Slice(points, func(i, j int) bool {
+			return (points[i].x-e.p1.x)*rx+(points[i].y-e.p1.y)*ry < (points[j].x-e.p1.x)*rx+(points[j].y-e.p1.y)*ry
+		})
+		for i := 1; i < len(points); i++ {
+			p1, p2 := points[i-1], points[i]
+			if p1 == p2 {
+				continue
+			}
+			key, dir := clip_key{p1, p2}, 1
+			if (p2.x < p1.x) || ((p2.x == p1.x) && (p2.y < p1.y)) {
+				key, dir = clip_key{p2, p1}, -1
+			}
+			s, ok := segment_map[key]
+			if !ok {
+				s = &clip_segment{key: key}
+				segment_map[key] = s
+				segments = append(segments, s)
+			}
+			s.winding[e.poly] += dir
+		}
+	}
+	out := segments[:0]
+	for _, s := range segments {
+		if (s.winding[0] != 0) || (s.winding[1] != 0) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func clip_inside(winding, fill_rule int) bool {
+	switch fill_rule {
+	case 0:
+		return (winding & 1) != 0
+	case 2:
+		return winding > 0
+	case 3:
+		return winding < 0
+	}
+	return winding != 0
+}
+
+func clip_op(s_in, c_in bool, op int) bool {
+	switch op {
+	case 0:
+		return s_in || c_in
+	case 1:
+		return s_in && c_in
+	case 2:
+		return s_in && !c_in
+	default:
+		return s_in != c_in
+	}
+}
+
+//find the windings either side of each segment by casting a ray from its mid point,
+//keep the segments that separate inside from outside, directed with the inside on the left
+func clip_classify(segments []*clip_segment, op, fill_rule int) []clip_key {
+	//cast along the axis that crosses the segment best, swapping x and y if vertical
+	swapped := make([]bool, len(segments), len(segments))
+	for i, s := range segments {
+		swapped[i] = math.Abs(s.key.hi.y-s.key.lo.y) < math.Abs(s.key.hi.x-s.key.lo.x)
+	}
+	windings := make([][2]int, len(segments), len(segments))
+	clip_rays(segments, swapped, false, windings)
+	clip_rays(segments, swapped, true, windings)
+	boundary := []clip_key{}
+	for i, s := range segments {
+		lo, hi := s.key.lo, s.key.hi
+		swap := swapped[i]
+		plus := windings[i]
+		//the other side also sees this segment crossing the ray
+		a, b := lo, hi
+		if swap {
+			a, b = point64{a.y, a.x}, point64{b.y, b.x}
+		}
+		minus := plus
+		for i := 0; i < 2; i++ {
+			if b.y > a.y {
+				minus[i] += s.winding[i]
+			} else {
+				minus[i] -= s.winding[i]
+			}
+		}
+		if swap {
+			//swapping the axes mirrors the plane, which negates the windings
+			for i := 0; i < 2; i++ {
+				plus[i], minus[i] = -plus[i], -minus[i]
+			}
+		}
+		in_plus := clip_op(clip_inside(plus[0], fill_rule), clip_inside(plus[1], fill_rule), op)
+		in_minus := clip_op(clip_inside(minus[0], fill_rule), clip_inside(minus[1], fill_rule), op)
+		if in_plus == in_minus {
+			continue
+		}
+		//inside on the plus side means running down the ray axis, in swapped space
+		if (b.y > a.y) == in_plus {
+			lo, hi = hi, lo
+		}
+		if swap {
+			lo, hi = hi, lo
+		}
+		boundary = append(boundary, clip_key{lo, hi})
+	}
+	return boundary
+}
+
+//windings crossed by a ray towards +x from the mid point of each segment cast in the
+//space given, x and y swapped or not. the rays are swept up y with a list of the
+//segments spanning the current y, so each ray only looks at segments it could cross
+func clip_rays(segments []*clip_segment, swapped []bool, swap bool, windings [][2]int) {
+	flip := func(p point64) point64 {
+		if swap {
+			return point64{p.y, p.x}
+		}
+		return p
+	}
+	rays := []int{}
+	for i := range segments {
+		if swapped[i] == swap {
+			rays = append(rays, i)
+		}
+	}
+	if len(rays) == 0 {
+		return
+	}
+	ends := make([][2]point64, len(segments), len(segments))
+	for i, s := range segments {
+		ends[i] = [2]point64{flip(s.key.lo), flip(s.key.hi)}
+	}
+	mid := func(i int) point64 {
+		a, b := ends[i][0], ends[i][1]
+		return point64{(a.x + b.x) * 0.5, (a.y + b.y) * 0.5}
+	}
+	sort.Slice(rays, func(i, j int) bool { return mid(rays[i]).y < mid(rays[j]).y })
+	order := make([]int, len(segments), len(segments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return math.Min(ends[order[i]][0].y, ends[order[i]][1].y) < math.Min(ends[order[j]][0].y, ends[order[j]][1].y)
+	})
+	active := []int{}
+	next := 0
+	for _, r := range rays {
+		m := mid(r)
+		for (next < len(order)) && (math.Min(ends[order[next]][0].y, ends[order[next]][1].y) <= m.y) {
+			active = append(active, order[next])
+			next++
+		}
+		//segments wholly below the ray are done with, the rest span its y
+		spanning := active[:0]
+		for _, o := range active {
+			a, b := ends[o][0], ends[o][1]
+			if math.Max(a.y, b.y) <= m.y {
+				continue
+			}
+			spanning = append(spanning, o)
+			if o == r {
+				continue
+			}
+			if a.x+(m.y-a.y)*(b.x-a.x)/(b.y-a.y) <= m.x {
+				continue
+			}
+			for i := 0; i < 2; i++ {
+				if b.y > a.y {
+					windings[r][i] += segments[o].winding[i]
+				} else {
+					windings[r][i] -= segments[o].winding[i]
+				}
+			}
+		}
+		active = spanning
+	}
+}
+
+//join directed boundary segments into closed contours,
+//taking the leftmost turn at shared vertices so touching contours stay apart
+func clip_link(boundary []clip_key) []*Points {
+	outgoing := map[point64][]int{}
+	for i, k := range boundary {
+		outgoing[k.lo] = append(outgoing[k.lo], i)
+	}
+	used := make([]bool, len(boundary), len(boundary))
+	contours := []*Points{}
+	for start := range boundary {
+		if used[start] {
+			continue
+		}
+		contour := []point64{boundary[start].lo}
+		e := start
+		for {
+			used[e] = true
+			k := boundary[e]
+			contour = append(contour, k.hi)
+			dx, dy := k.hi.x-k.lo.x, k.hi.y-k.lo.y
+			next := -1
+			best := math.Inf(-1)
+			for _, o := range outgoing[k.hi] {
+				if used[o] {
+					continue
+				}
+				ok := boundary[o]
+				ox, oy := ok.hi.x-ok.lo.x, ok.hi.y-ok.lo.y
+				turn := math.Atan2(dx*oy-dy*ox, dx*ox+dy*oy)
+				if turn > best {
+					best, next = turn, o
+				}
+			}
+			if next == -1 {
+				break
+			}
+			e = next
+		}
+		//points stay in float64 until here, rounding can bring neighbours together
+		out_points := Points{}
+		for _, p := range clip_simplify(contour) {
+			pp := &Point{float32(p.x), float32(p.y)}
+			if (len(out_points) == 0) || !Equal_2d(out_points[len(out_points)-1], pp) {
+				out_points = append(out_points, pp)
+			}
+		}
+		if len(out_points) < 4 {
+			continue
+		}
+		contours = append(contours, &out_points)
+	}
+	return contours
+}
+
+//drop collinear vertices left behind by edge splitting, keeping the contour closed
+func clip_simplify(contour []point64) []point64 {
+	if (len(contour) < 4) || (contour[0] != contour[len(contour)-1]) {
+		return contour
+	}
+	c := contour[:len(contour)-1]
+	for changed := true; changed && (len(c) > 2); {
+		changed = false
+		for i := 0; i < len(c); i++ {
+			if tri_cross(c[(i+len(c)-1)%len(c)], c[i], c[(i+1)%len(c)]) == 0.0 {
+				c = append(c[:i], c[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+	return append(c, c[0])
+}
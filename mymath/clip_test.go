@@ -0,0 +1,113 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+func test_square(x, y, size float32) *Points {
+	return &Points{&Point{x, y}, &Point{x + size, y}, &Point{x + size, y + size}, &Point{x, y + size}}
+}
+
+//counter clockwise polygon round a circle, the points a little irregular so
+//there are crossings at all angles
+func test_circle(x, y, radius float32, n int) *Points {
+	points := make(Points, n, n)
+	for i := range points {
+		a := float64(i) * 2.0 * math.Pi / float64(n)
+		r := float64(radius) * (1.0 + 0.01*math.Sin(float64(i)*1.7))
+		points[i] = &Point{x + float32(r*math.Cos(a)), y + float32(r*math.Sin(a))}
+	}
+	return &points
+}
+
+func total_area(contours []*Points) float32 {
+	area := float32(0.0)
+	for _, c := range contours {
+		area += Polygon_area(c)
+	}
+	return area
+}
+
+///////
+//tests
+///////
+
+func TestClip_squares(t *testing.T) {
+	subject := []*Points{test_square(0.0, 0.0, 10.0)}
+	clip := []*Points{test_square(5.0, 5.0, 10.0)}
+	hole := []*Points{test_square(0.0, 0.0, 10.0), {&Point{2.0, 2.0}, &Point{2.0, 4.0}, &Point{4.0, 4.0}, &Point{4.0, 2.0}}}
+	tests := []struct {
+		name          string
+		subject, clip []*Points
+		op            int
+		area          float32
+		contours      int
+	}{
+		{"union", subject, clip, 0, 175.0, 1},
+		{"intersection", subject, clip, 1, 25.0, 1},
+		{"difference", subject, clip, 2, 75.0, 1},
+		{"reverse difference", clip, subject, 2, 75.0, 1},
+		{"xor", subject, clip, 3, 150.0, 2},
+		{"union apart", subject, []*Points{test_square(20.0, 0.0, 10.0)}, 0, 200.0, 2},
+		{"intersection apart", subject, []*Points{test_square(20.0, 0.0, 10.0)}, 1, 0.0, 0},
+		{"union sharing an edge", subject, []*Points{test_square(10.0, 0.0, 10.0)}, 0, 200.0, 1},
+		{"union with a hole", hole, clip, 0, 171.0, 2},
+		{"difference with a hole", hole, clip, 2, 71.0, 2},
+	}
+	for _, test := range tests {
+		result := Clip_polygons(test.subject, test.clip, test.op, 1)
+		if len(result) != test.contours {
+			t.Errorf("%s: %d contours, want %d", test.name, len(result), test.contours)
+		}
+		if area := total_area(result); math.Abs(float64(area-test.area)) > 0.001 {
+			t.Errorf("%s: area %v, want %v", test.name, area, test.area)
+		}
+	}
+}
+
+//many crossings at odd angles, the areas of the results must agree with each other
+func TestClip_circles(t *testing.T) {
+	a := []*Points{test_circle(0.0, 0.0, 100.0, 997)}
+	b := []*Points{test_circle(60.0, 30.0, 80.0, 1009)}
+	area_a, area_b := total_area(a), total_area(b)
+	union := total_area(Clip_polygons(a, b, 0, 1))
+	inter := total_area(Clip_polygons(a, b, 1, 1))
+	diff := total_area(Clip_polygons(a, b, 2, 1))
+	xor := total_area(Clip_polygons(a, b, 3, 1))
+	near := func(x, y float32) bool {
+		return math.Abs(float64(x-y)) < 0.5
+	}
+	if (inter <= 0.0) || (inter >= area_b) {
+		t.Fatalf("intersection area %v out of range", inter)
+	}
+	if !near(union, area_a+area_b-inter) {
+		t.Errorf("union area %v, want %v", union, area_a+area_b-inter)
+	}
+	if !near(diff, area_a-inter) {
+		t.Errorf("difference area %v, want %v", diff, area_a-inter)
+	}
+	if !near(xor, union-inter) {
+		t.Errorf("xor area %v, want %v", xor, union-inter)
+	}
+}
+
+////////////
+//benchmarks
+////////////
+
+func BenchmarkClip_circles(b *testing.B) {
+	p1 := []*Points{test_circle(0.0, 0.0, 100.0, 5000)}
+	p2 := []*Points{test_circle(60.0, 30.0, 80.0, 5000)}
+	for i := 0; i < b.N; i++ {
+		Clip_polygons(p1, p2, 0, 1)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math/big"
+	"math/bits"
+)
+
+/////////////////////////
+//public structures/types
+/////////////////////////
+
+//float64 point, for geometry too large or far out for float32
+type Vec64 struct {
+	X float64
+	Y float64
+}
+
+//fixed point integer point, as the nanometre units of pcb tools
+type Ipoint struct {
+	X int64
+	Y int64
+}
+
+//////////////////
+//public functions
+//////////////////
+
+///////////////
+//float64 lines
+///////////////
+
+//robust segment intersection backs the distance test, so touching lines always collide
+func Collide_thick_lines_vec64(tl1_p1, tl1_p2, tl2_p1, tl2_p2 Vec64, r float64) bool {
+	c1, c2 := closest64(point64{tl1_p1.X, tl1_p1.Y}, point64{tl1_p2.X, tl1_p2.Y},
+		point64{tl2_p1.X, tl2_p1.Y}, point64{tl2_p2.X, tl2_p2.Y})
+	dx, dy := c1.x-c2.x, c1.y-c2.y
+	return dx*dx+dy*dy <= r*r
+}
+
+func Collide_tapered_lines_vec64(tl1_p1, tl1_p2 Vec64, tl1_r1, tl1_r2 float64, tl2_p1, tl2_p2 Vec64, tl2_r1, tl2_r2 float64) bool {
+	return tapered64(point64{tl1_p1.X, tl1_p1.Y}, point64{tl1_p2.X, tl1_p2.Y}, tl1_r1, tl1_r2,
+		point64{tl2_p1.X, tl2_p1.Y}, point64{tl2_p2.X, tl2_p2.Y}, tl2_r1, tl2_r2)
+}
+
+func Contact_thick_lines_vec64(tl1_p1, tl1_p2, tl2_p1, tl2_p2 Vec64, r float64) *Contact {
+	return contact64(point64{tl1_p1.X, tl1_p1.Y}, point64{tl1_p2.X, tl1_p2.Y},
+		point64{tl2_p1.X, tl2_p1.Y}, point64{tl2_p2.X, tl2_p2.Y}, r)
+}
+
+///////////////
+//integer lines
+///////////////
+
+//exact orientation of p3 relative to the directed line p1 to p2,
+//1 if to the left, -1 if to the right and 0 if collinear
+func Orient_i64(p1, p2, p3 Ipoint) int {
+	if small_i64(0, p1, p2, p3) {
+		det := (p2.X-p1.X)*(p3.Y-p1.Y) - (p2.Y-p1.Y)*(p3.X-p1.X)
+		switch {
+		case det > 0:
+			return 1
+		case det < 0:
+			return -1
+		}
+		return 0
+	}
+	left := new(big.Int).Mul(big_sub(p2.X, p1.X), big_sub(p3.Y, p1.Y))
+	right := new(big.Int).Mul(big_sub(p2.Y, p1.Y), big_sub(p3.X, p1.X))
+	return left.Cmp(right)
+}
+
+//exact segment intersection test, touching and overlapping segments intersect
+func Collide_lines_i64(l1_p1, l1_p2, l2_p1, l2_p2 Ipoint) bool {
+	o1 := Orient_i64(l1_p1, l1_p2, l2_p1)
+	o2 := Orient_i64(l1_p1, l1_p2, l2_p2)
+	o3 := Orient_i64(l2_p1, l2_p2, l1_p1)
+	o4 := Orient_i64(l2_p1, l2_p2, l1_p2)
+	if (o1*o2 < 0) && (o3*o4 < 0) {
+		return true
+	}
+	return ((o1 == 0) && on_segment_i64(l1_p1, l1_p2, l2_p1)) ||
+		((o2 == 0) && on_segment_i64(l1_p1, l1_p2, l2_p2)) ||
+		((o3 == 0) && on_segment_i64(l2_p1, l2_p2, l1_p1)) ||
+		((o4 == 0) && on_segment_i64(l2_p1, l2_p2, l1_p2))
+}
+
+//exact test of whether p is within r of the segment p1 to p2
+func Within_line_i64(p, p1, p2 Ipoint, r int64) bool {
+	if small_i64(r, p, p1, p2) {
+		lx, ly := p2.X-p1.X, p2.Y-p1.Y
+		px, py := p.X-p1.X, p.Y-p1.Y
+		r2 := uint64(r * r)
+		c1 := px*lx + py*ly
+		if c1 <= 0 {
+			return uint64(px*px+py*py) <= r2
+		}
+		c2 := lx*lx + ly*ly
+		if c2 <= c1 {
+			qx, qy := p.X-p2.X, p.Y-p2.Y
+			return uint64(qx*qx+qy*qy) <= r2
+		}
+		//cross squared against r squared times the length squared, in 128 bits
+		cross := px*ly - py*lx
+		if cross < 0 {
+			cross = -cross
+		}
+		h1, l1 := bits.Mul64(uint64(cross), uint64(cross))
+		h2, l2 := bits.Mul64(r2, uint64(c2))
+		return (h1 < h2) || ((h1 == h2) && (l1 <= l2))
+	}
+	lx, ly := big_sub(p2.X, p1.X), big_sub(p2.Y, p1.Y)
+	px, py := big_sub(p.X, p1.X), big_sub(p.Y, p1.Y)
+	rr := new(big.Int).Mul(big.NewInt(r), big.NewInt(r))
+	c1 := big_dot(px, py, lx, ly)
+	if c1.Sign() <= 0 {
+		return big_dot(px, py, px, py).Cmp(rr) <= 0
+	}
+	c2 := big_dot(lx, ly, lx, ly)
+	if c2.Cmp(c1) <= 0 {
+		qx, qy := big_sub(p.X, p2.X), big_sub(p.Y, p2.Y)
+		return big_dot(qx, qy, qx, qy).Cmp(rr) <= 0
+	}
+	cross := new(big.Int).Sub(new(big.Int).Mul(px, ly), new(big.Int).Mul(py, lx))
+	cross.Mul(cross, cross)
+	return cross.Cmp(rr.Mul(rr, c2)) <= 0
+}
+
+//exact thick line collision, r is the sum of the radii and any gap
+func Collide_thick_lines_i64(tl1_p1, tl1_p2, tl2_p1, tl2_p2 Ipoint, r int64) bool {
+	if Collide_lines_i64(tl1_p1, tl1_p2, tl2_p1, tl2_p2) {
+		return true
+	}
+	return Within_line_i64(tl2_p1, tl1_p1, tl1_p2, r) ||
+		Within_line_i64(tl2_p2, tl1_p1, tl1_p2, r) ||
+		Within_line_i64(tl1_p1, tl2_p1, tl2_p2, r) ||
+		Within_line_i64(tl1_p2, tl2_p1, tl2_p2, r)
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//true if coordinate differences and products all fit the fast int64 paths
+func small_i64(r int64, points ...Ipoint) bool {
+	const limit = 1 << 29
+	if (r < 0) || (r >= limit*2) {
+		return false
+	}
+	for _, p := range points {
+		if (p.X <= -limit) || (p.X >= limit) || (p.Y <= -limit) || (p.Y >= limit) {
+			return false
+		}
+	}
+	return true
+}
+
+//true if p, known to be collinear with p1 and p2, lies within their bounds
+func on_segment_i64(p1, p2, p Ipoint) bool {
+	minx, maxx := p1.X, p2.X
+	if minx > maxx {
+		minx, maxx = maxx, minx
+	}
+	miny, maxy := p1.Y, p2.Y
+	if miny > maxy {
+		miny, maxy = maxy, miny
+	}
+	return (p.X >= minx) && (p.X <= maxx) && (p.Y >= miny) && (p.Y <= maxy)
+}
+
+func big_sub(a, b int64) *big.Int {
+	return new(big.Int).Sub(big.NewInt(a), big.NewInt(b))
+}
+
+func big_dot(ax, ay, bx, by *big.Int) *big.Int {
+	d := new(big.Int).Mul(ax, bx)
+	return d.Add(d, new(big.Int).Mul(ay, by))
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+//orientation worked out in big integers throughout
+func big_orient(p1, p2, p3 Ipoint) int {
+	left := new(big.Int).Mul(big_sub(p2.X, p1.X), big_sub(p3.Y, p1.Y))
+	right := new(big.Int).Mul(big_sub(p2.Y, p1.Y), big_sub(p3.X, p1.X))
+	return left.Cmp(right)
+}
+
+//point to segment distance against r in exact rationals
+func rat_within(p, p1, p2 Ipoint, r int64) bool {
+	rat := func(x int64) *big.Rat { return new(big.Rat).SetInt64(x) }
+	sub := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+	mul := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+	add := func(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+	lx, ly := sub(rat(p2.X), rat(p1.X)), sub(rat(p2.Y), rat(p1.Y))
+	px, py := sub(rat(p.X), rat(p1.X)), sub(rat(p.Y), rat(p1.Y))
+	l2 := add(mul(lx, lx), mul(ly, ly))
+	t := new(big.Rat)
+	if l2.Sign() != 0 {
+		t = new(big.Rat).Quo(add(mul(px, lx), mul(py, ly)), l2)
+		if t.Sign() < 0 {
+			t = rat(0)
+		} else if t.Cmp(rat(1)) > 0 {
+			t = rat(1)
+		}
+	}
+	dx, dy := sub(px, mul(lx, t)), sub(py, mul(ly, t))
+	return add(mul(dx, dx), mul(dy, dy)).Cmp(mul(rat(r), rat(r))) <= 0
+}
+
+///////
+//tests
+///////
+
+func TestExact_i64_extremes(t *testing.T) {
+	const min, max = math.MinInt64, math.MaxInt64
+	o := func(x, y int64) Ipoint { return Ipoint{x, y} }
+	orients := []struct {
+		p1, p2, p3 Ipoint
+		want       int
+	}{
+		{o(min, min), o(max, max), o(0, 0), 0},
+		{o(min, min), o(max-1, max-1), o(0, 0), 0},
+		{o(min, min), o(max-1, max-1), o(0, 1), 1},
+		{o(min, min), o(max-1, max-1), o(1, 0), -1},
+		{o(min, max), o(max, min), o(max, max), 1},
+		{o(max, max), o(min, max), o(0, min), 1},
+	}
+	for _, test := range orients {
+		if got := Orient_i64(test.p1, test.p2, test.p3); got != test.want {
+			t.Errorf("orient %v %v %v = %d, want %d", test.p1, test.p2, test.p3, got, test.want)
+		}
+		if got := big_orient(test.p1, test.p2, test.p3); got != test.want {
+			t.Errorf("reference orient %v %v %v = %d, want %d", test.p1, test.p2, test.p3, got, test.want)
+		}
+	}
+	collides := []struct {
+		name           string
+		a1, a2, b1, b2 Ipoint
+		r              int64
+		want           bool
+	}{
+		{"crossing corners", o(min, min), o(max, max), o(min, max), o(max, min), 0, true},
+		{"parallel by one", o(min, min), o(max-1, max-1), o(min, min+1), o(max-1, max), 0, false},
+		{"parallel within one", o(min, min), o(max-1, max-1), o(min, min+1), o(max-1, max), 1, true},
+		{"end to end", o(min, min), o(0, 0), o(0, 0), o(max, max), 0, true},
+		{"top and bottom", o(min, min), o(max, min), o(min, max), o(max, max), max, false},
+		{"just reaching", o(min, 0), o(max, 0), o(0, max), o(0, max), max, true},
+		{"just short", o(min, -1), o(max, -1), o(0, max), o(0, max), max, false},
+	}
+	for _, test := range collides {
+		if got := Collide_thick_lines_i64(test.a1, test.a2, test.b1, test.b2, test.r); got != test.want {
+			t.Errorf("%s: collide %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+//the int64 fast paths agree with exact rationals, near and past where they hand over to big integers
+func TestExact_i64_random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, limit := range []int64{100, 1 << 28, 1 << 29, 1 << 40, math.MaxInt64} {
+		coord := func() int64 {
+			if limit == math.MaxInt64 {
+				return int64(rng.Uint64())
+			}
+			return rng.Int63n(limit*2) - limit
+		}
+		for n := 0; n < 2000; n++ {
+			p, p1, p2 := Ipoint{coord(), coord()}, Ipoint{coord(), coord()}, Ipoint{coord(), coord()}
+			if n%4 == 0 {
+				//exactly collinear
+				p = Ipoint{p1.X + (p2.X-p1.X)/2, p1.Y + (p2.Y-p1.Y)/2}
+			}
+			if got, want := Orient_i64(p1, p2, p), big_orient(p1, p2, p); got != want {
+				t.Fatalf("orient %v %v %v = %d, want %d", p1, p2, p, got, want)
+			}
+			r := coord()
+			if r < 0 {
+				r = -r
+			}
+			if got, want := Within_line_i64(p, p1, p2, r), rat_within(p, p1, p2, r); got != want {
+				t.Fatalf("within %v of %v %v by %d = %v, want %v", p, p1, p2, r, got, want)
+			}
+		}
+	}
+}
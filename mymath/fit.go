@@ -0,0 +1,201 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+//////////////////
+//public functions
+//////////////////
+
+//fit cubic beziers to a sampled path so no sample is further than tolerance from
+//the curve, see Schneider, an algorithm for automatically fitting digitized curves.
+//returns the control points, 3n+1 of them with each curve sharing its end points
+func Fit_beziers(pathp *Points, tolerance float32) *Points {
+	path := spline_points(pathp)
+	d := make([]point64, len(path), len(path))
+	for i, pp := range path {
+		p := *pp
+		d[i] = point64{float64(p[0]), float64(p[1])}
+	}
+	out_points := Points{}
+	if len(d) == 0 {
+		return &out_points
+	}
+	out_points = append(out_points, &Point{float32(d[0].x), float32(d[0].y)})
+	if len(d) == 1 {
+		return &out_points
+	}
+	t1 := fit_norm(fit_sub(d[1], d[0]))
+	t2 := fit_norm(fit_sub(d[len(d)-2], d[len(d)-1]))
+	tol := float64(tolerance)
+	for _, p := range fit_cubic(d, t1, t2, tol*tol, nil) {
+		out_points = append(out_points, &Point{float32(p.x), float32(p.y)})
+	}
+	return &out_points
+}
+
+//flatten a run of cubic beziers that share end points, as from Fit_beziers
+func Beziers_as_lines(ctrlp *Points, distance_tolerance float32) *Points {
+	ctrl := *ctrlp
+	points := Points{}
+	if len(ctrl) == 0 {
+		return &points
+	}
+	points = append(points, &Point{(*ctrl[0])[0], (*ctrl[0])[1]})
+	for i := 0; i+3 < len(ctrl); i += 3 {
+		points = append(points, (*Bezier_path_as_lines(ctrl[i], ctrl[i+1], ctrl[i+2], ctrl[i+3], distance_tolerance))[1:]...)
+	}
+	return &points
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func fit_sub(a, b point64) point64 {
+	return point64{a.x - b.x, a.y - b.y}
+}
+
+func fit_add(a, b point64) point64 {
+	return point64{a.x + b.x, a.y + b.y}
+}
+
+func fit_scale(a point64, s float64) point64 {
+	return point64{a.x * s, a.y * s}
+}
+
+func fit_dot(a, b point64) float64 {
+	return a.x*b.x + a.y*b.y
+}
+
+func fit_norm(a point64) point64 {
+	l := math.Sqrt(fit_dot(a, a))
+	if l == 0.0 {
+		return a
+	}
+	return fit_scale(a, 1.0/l)
+}
+
+//point on a bezier of any degree by de casteljau
+func fit_eval(ctrl []point64, t float64) point64 {
+	tmp := append([]point64{}, ctrl...)
+	for n := len(tmp) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			tmp[i] = fit_add(fit_scale(tmp[i], 1.0-t), fit_scale(tmp[i+1], t))
+		}
+	}
+	return tmp[0]
+}
+
+//fit one cubic to the samples, splitting at the worst sample when it will not do,
+//appends the three control points after the first end point of each curve
+func fit_cubic(d []point64, t1, t2 point64, tol2 float64, out []point64) []point64 {
+	if len(d) == 2 {
+		dist := math.Sqrt(fit_dot(fit_sub(d[1], d[0]), fit_sub(d[1], d[0]))) / 3.0
+		return append(out, fit_add(d[0], fit_scale(t1, dist)), fit_add(d[1], fit_scale(t2, dist)), d[1])
+	}
+	u := fit_chord_params(d)
+	bez := fit_generate(d, u, t1, t2)
+	max_error, split := fit_max_error(d, bez, u)
+	if max_error < tol2 {
+		return append(out, bez[1], bez[2], bez[3])
+	}
+	//close enough to be worth improving the parameters before splitting
+	if max_error < tol2*16.0 {
+		for i := 0; i < 4; i++ {
+			u = fit_reparameterize(d, bez, u)
+			bez = fit_generate(d, u, t1, t2)
+			max_error, split = fit_max_error(d, bez, u)
+			if max_error < tol2 {
+				return append(out, bez[1], bez[2], bez[3])
+			}
+		}
+	}
+	tc := fit_norm(fit_sub(d[split-1], d[split+1]))
+	if fit_dot(tc, tc) == 0.0 {
+		tc = fit_norm(point64{-(d[split].y - d[split-1].y), d[split].x - d[split-1].x})
+	}
+	out = fit_cubic(d[:split+1], t1, tc, tol2, out)
+	return fit_cubic(d[split:], fit_scale(tc, -1.0), t2, tol2, out)
+}
+
+//parameters from the cumulative chord length
+func fit_chord_params(d []point64) []float64 {
+	u := make([]float64, len(d), len(d))
+	for i := 1; i < len(d); i++ {
+		v := fit_sub(d[i], d[i-1])
+		u[i] = u[i-1] + math.Sqrt(fit_dot(v, v))
+	}
+	for i := range u {
+		u[i] /= u[len(u)-1]
+	}
+	return u
+}
+
+//least squares fit of the inner control points along the end tangents
+func fit_generate(d []point64, u []float64, t1, t2 point64) []point64 {
+	first, last := d[0], d[len(d)-1]
+	var c [2][2]float64
+	var x [2]float64
+	for i, t := range u {
+		s := 1.0 - t
+		b0, b1, b2, b3 := s*s*s, 3.0*t*s*s, 3.0*t*t*s, t*t*t
+		a1, a2 := fit_scale(t1, b1), fit_scale(t2, b2)
+		c[0][0] += fit_dot(a1, a1)
+		c[0][1] += fit_dot(a1, a2)
+		c[1][1] += fit_dot(a2, a2)
+		tmp := fit_sub(d[i], fit_add(fit_scale(first, b0+b1), fit_scale(last, b2+b3)))
+		x[0] += fit_dot(a1, tmp)
+		x[1] += fit_dot(a2, tmp)
+	}
+	c[1][0] = c[0][1]
+	det := c[0][0]*c[1][1] - c[1][0]*c[0][1]
+	alpha1, alpha2 := 0.0, 0.0
+	if det != 0.0 {
+		alpha1 = (x[0]*c[1][1] - x[1]*c[0][1]) / det
+		alpha2 = (c[0][0]*x[1] - c[1][0]*x[0]) / det
+	}
+	//fall back to a third of the chord if the fit is degenerate or loops back
+	v := fit_sub(last, first)
+	seg_length := math.Sqrt(fit_dot(v, v))
+	epsilon := 1.0e-6 * seg_length
+	if (alpha1 < epsilon) || (alpha2 < epsilon) {
+		alpha1, alpha2 = seg_length/3.0, seg_length/3.0
+	}
+	return []point64{first, fit_add(first, fit_scale(t1, alpha1)), fit_add(last, fit_scale(t2, alpha2)), last}
+}
+
+//worst squared distance of a sample from the curve, and its index
+func fit_max_error(d []point64, bez []point64, u []float64) (float64, int) {
+	max_error := 0.0
+	split := len(d) / 2
+	for i := 1; i < len(d)-1; i++ {
+		v := fit_sub(fit_eval(bez, u[i]), d[i])
+		if dist := fit_dot(v, v); dist >= max_error {
+			max_error, split = dist, i
+		}
+	}
+	return max_error, split
+}
+
+//one newton raphson step per sample towards its closest point on the curve
+func fit_reparameterize(d []point64, bez []point64, u []float64) []float64 {
+	q1 := []point64{fit_scale(fit_sub(bez[1], bez[0]), 3.0), fit_scale(fit_sub(bez[2], bez[1]), 3.0), fit_scale(fit_sub(bez[3], bez[2]), 3.0)}
+	q2 := []point64{fit_scale(fit_sub(q1[1], q1[0]), 2.0), fit_scale(fit_sub(q1[2], q1[1]), 2.0)}
+	out := make([]float64, len(u), len(u))
+	for i, t := range u {
+		p, p1, p2 := fit_eval(bez, t), fit_eval(q1, t), fit_eval(q2, t)
+		diff := fit_sub(p, d[i])
+		denom := fit_dot(p1, p1) + fit_dot(diff, p2)
+		out[i] = t
+		if denom != 0.0 {
+			out[i] = math.Max(0.0, math.Min(1.0, t-fit_dot(diff, p1)/denom))
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+//distance from p to the nearest line of the path
+func distance_to_path(p *Point, pathp *Points) float32 {
+	path := *pathp
+	d := float32(math.MaxFloat32)
+	for i := 1; i < len(path); i++ {
+		if l := Distance_to_line_2d(p, path[i-1], path[i]); l < d {
+			d = l
+		}
+	}
+	return d
+}
+
+///////
+//tests
+///////
+
+//every sample is within tolerance of the fitted curves, with far fewer points
+func TestFit_beziers(t *testing.T) {
+	wave := Points{}
+	for i := 0; i <= 200; i++ {
+		x := float64(i)
+		wave = append(wave, &Point{float32(x), float32(20.0 * math.Sin(x*0.05))})
+	}
+	corner := Points{&Point{0.0, 0.0}, &Point{50.0, 0.0}, &Point{100.0, 0.0}, &Point{100.0, 50.0}, &Point{100.0, 100.0}}
+	for _, path := range []Points{wave, corner, *test_path(100)} {
+		for _, tolerance := range []float32{0.1, 1.0, 5.0} {
+			ctrl := Fit_beziers(&path, tolerance)
+			if (len(*ctrl)-1)%3 != 0 {
+				t.Fatalf("%d control points, want 3n+1", len(*ctrl))
+			}
+			if len(*ctrl) >= len(path)*3 {
+				t.Errorf("%d control points for %d samples", len(*ctrl), len(path))
+			}
+			curve := Beziers_as_lines(ctrl, 0.01)
+			for _, p := range path {
+				if d := distance_to_path(p, curve); d > tolerance+0.05 {
+					t.Errorf("tolerance %v: sample %v is %v from the curve", tolerance, p, d)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestFit_beziers_short(t *testing.T) {
+	tests := []struct {
+		path *Points
+		want int
+	}{
+		{&Points{}, 0},
+		{&Points{&Point{1.0, 2.0}}, 1},
+		{&Points{&Point{1.0, 2.0}, &Point{5.0, 2.0}}, 4},
+	}
+	for _, test := range tests {
+		if ctrl := Fit_beziers(test.path, 1.0); len(*ctrl) != test.want {
+			t.Errorf("%d points give %d control points, want %d", len(*test.path), len(*ctrl), test.want)
+		}
+	}
+}
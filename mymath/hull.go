@@ -0,0 +1,159 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"sort"
+)
+
+//////////////////
+//public functions
+//////////////////
+
+//convex hull by andrew's monotone chain, counter clockwise with no collinear points
+//and no repeated end point. fewer than three points come back as the distinct points
+func Convex_hull(pathp *Points) *Points {
+	out_points := Points{}
+	for _, p := range hull64(pathp) {
+		out_points = append(out_points, &Point{float32(p.x), float32(p.y)})
+	}
+	return &out_points
+}
+
+//minimum area rectangle at any angle enclosing a path, its four corners counter
+//clockwise. one side always lies along a hull edge, so each edge is tried in turn
+func Min_area_rect(pathp *Points) *Points {
+	hull := hull64(pathp)
+	if len(hull) == 0 {
+		return &Points{}
+	}
+	best := math.Inf(1)
+	var corners [4]point64
+	for i := range hull {
+		ux, uy := 1.0, 0.0
+		if len(hull) > 1 {
+			e := fit_norm(fit_sub(hull[(i+1)%len(hull)], hull[i]))
+			ux, uy = e.x, e.y
+		}
+		minu, maxu := math.Inf(1), math.Inf(-1)
+		minv, maxv := math.Inf(1), math.Inf(-1)
+		for _, p := range hull {
+			u := p.x*ux + p.y*uy
+			v := p.y*ux - p.x*uy
+			minu, maxu = math.Min(minu, u), math.Max(maxu, u)
+			minv, maxv = math.Min(minv, v), math.Max(maxv, v)
+		}
+		if area := (maxu - minu) * (maxv - minv); area < best {
+			best = area
+			corner := func(u, v float64) point64 { return point64{u*ux - v*uy, u*uy + v*ux} }
+			corners = [4]point64{corner(minu, minv), corner(maxu, minv), corner(maxu, maxv), corner(minu, maxv)}
+		}
+	}
+	out_points := Points{}
+	for _, p := range corners {
+		out_points = append(out_points, &Point{float32(p.x), float32(p.y)})
+	}
+	return &out_points
+}
+
+//smallest circle enclosing a path, its centre and radius. welzl's algorithm run
+//over the hull points only
+func Min_enclosing_circle(pathp *Points) (*Point, float32) {
+	hull := hull64(pathp)
+	if len(hull) == 0 {
+		return &Point{0.0, 0.0}, 0.0
+	}
+	c, r := hull[0], 0.0
+	for i := 1; i < len(hull); i++ {
+		if circle_contains64(c, r, hull[i]) {
+			continue
+		}
+		c, r = hull[i], 0.0
+		for j := 0; j < i; j++ {
+			if circle_contains64(c, r, hull[j]) {
+				continue
+			}
+			c = point64{(hull[i].x + hull[j].x) * 0.5, (hull[i].y + hull[j].y) * 0.5}
+			r = math.Hypot(hull[i].x-c.x, hull[i].y-c.y)
+			for k := 0; k < j; k++ {
+				if circle_contains64(c, r, hull[k]) {
+					continue
+				}
+				c, r = circumcircle64(hull[i], hull[j], hull[k])
+			}
+		}
+	}
+	return &Point{float32(c.x), float32(c.y)}, float32(r)
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func hull64(pathp *Points) []point64 {
+	points := []point64{}
+	for _, pp := range *pathp {
+		p := *pp
+		points = append(points, point64{float64(p[0]), float64(p[1])})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].x != points[j].x {
+			return points[i].x < points[j].x
+		}
+		return points[i].y < points[j].y
+	})
+	unique := []point64{}
+	for _, p := range points {
+		if (len(unique) == 0) || (unique[len(unique)-1] != p) {
+			unique = append(unique, p)
+		}
+	}
+	if len(unique) < 3 {
+		return unique
+	}
+	//lower then upper chain, each drops points that do not turn left
+	hull := []point64{}
+	for _, p := range unique {
+		for (len(hull) >= 2) && (orient64(hull[len(hull)-2], hull[len(hull)-1], p) <= 0) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(unique) - 2; i >= 0; i-- {
+		p := unique[i]
+		for (len(hull) >= lower) && (orient64(hull[len(hull)-2], hull[len(hull)-1], p) <= 0) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+func circle_contains64(c point64, r float64, p point64) bool {
+	return math.Hypot(p.x-c.x, p.y-c.y) <= r*(1.0+1.0e-12)+1.0e-9
+}
+
+//circle through three points, collinear points give the circle on the furthest pair
+func circumcircle64(a, b, c point64) (point64, float64) {
+	bx, by := b.x-a.x, b.y-a.y
+	cx, cy := c.x-a.x, c.y-a.y
+	d := 2.0 * (bx*cy - by*cx)
+	if d == 0.0 {
+		p1, p2 := a, b
+		for _, pair := range [][2]point64{{a, c}, {b, c}} {
+			if math.Hypot(pair[1].x-pair[0].x, pair[1].y-pair[0].y) > math.Hypot(p2.x-p1.x, p2.y-p1.y) {
+				p1, p2 = pair[0], pair[1]
+			}
+		}
+		m := point64{(p1.x + p2.x) * 0.5, (p1.y + p2.y) * 0.5}
+		return m, math.Hypot(p1.x-m.x, p1.y-m.y)
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux := (cy*b2 - by*c2) / d
+	uy := (bx*c2 - cx*b2) / d
+	return point64{a.x + ux, a.y + uy}, math.Hypot(ux, uy)
+}
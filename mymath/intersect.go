@@ -0,0 +1,228 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"math/big"
+)
+
+/////////////////////////
+//public structures/types
+/////////////////////////
+
+//result of a segment intersection, Count is 0 for none, 1 for a single point
+//and 2 for a collinear overlap running from P1 to P2. T and U are the
+//parameters of P1 and P2 along the first and second segment
+type Intersection struct {
+	Count int
+	P1    *Point
+	P2    *Point
+	T1    float32
+	T2    float32
+	U1    float32
+	U2    float32
+}
+
+//////////////////
+//public functions
+//////////////////
+
+//robust orientation of pp3 relative to the directed line pp1 to pp2,
+//1 if to the left, -1 if to the right and 0 if exactly collinear
+func Orient_2d(pp1, pp2, pp3 *Point) int {
+	p1, p2, p3 := *pp1, *pp2, *pp3
+	return orient64(point64{float64(p1[0]), float64(p1[1])},
+		point64{float64(p2[0]), float64(p2[1])},
+		point64{float64(p3[0]), float64(p3[1])})
+}
+
+func Intersect_lines_2d(pl1_p1, pl1_p2, pl2_p1, pl2_p2 *Point) *Intersection {
+	l1_p1, l1_p2, l2_p1, l2_p2 := *pl1_p1, *pl1_p2, *pl2_p1, *pl2_p2
+	a1 := point64{float64(l1_p1[0]), float64(l1_p1[1])}
+	a2 := point64{float64(l1_p2[0]), float64(l1_p2[1])}
+	b1 := point64{float64(l2_p1[0]), float64(l2_p1[1])}
+	b2 := point64{float64(l2_p2[0]), float64(l2_p2[1])}
+	count, p1, p2 := intersect64(a1, a2, b1, b2)
+	i := &Intersection{Count: count}
+	if count == 0 {
+		return i
+	}
+	i.P1 = &Point{float32(p1.x), float32(p1.y)}
+	i.T1 = float32(param64(a1, a2, p1))
+	i.U1 = float32(param64(b1, b2, p1))
+	if count == 2 {
+		i.P2 = &Point{float32(p2.x), float32(p2.y)}
+		i.T2 = float32(param64(a1, a2, p2))
+		i.U2 = float32(param64(b1, b2, p2))
+	}
+	return i
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//adaptive orientation, exact arithmetic only when the float result is in doubt
+func orient64(a, b, c point64) int {
+	detleft := (b.x - a.x) * (c.y - a.y)
+	detright := (b.y - a.y) * (c.x - a.x)
+	det := detleft - detright
+	errbound := 3.3306690738754716e-16 * (math.Abs(detleft) + math.Abs(detright))
+	if (det > errbound) || (-det > errbound) {
+		if det > 0.0 {
+			return 1
+		}
+		return -1
+	}
+	//no exact answer for NaN or infinite points, call them collinear
+	if !finite64(a, b, c) {
+		return 0
+	}
+	r := func(f float64) *big.Rat { return new(big.Rat).SetFloat64(f) }
+	bax := new(big.Rat).Sub(r(b.x), r(a.x))
+	bay := new(big.Rat).Sub(r(b.y), r(a.y))
+	cax := new(big.Rat).Sub(r(c.x), r(a.x))
+	cay := new(big.Rat).Sub(r(c.y), r(a.y))
+	left := new(big.Rat).Mul(bax, cay)
+	right := new(big.Rat).Mul(bay, cax)
+	return left.Cmp(right)
+}
+
+//true if no point has a NaN or infinite coordinate
+func finite64(points ...point64) bool {
+	for _, p := range points {
+		if math.IsNaN(p.x) || math.IsInf(p.x, 0) || math.IsNaN(p.y) || math.IsInf(p.y, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+//parameter of p along the segment a to b, clamped to the segment
+func param64(a, b, p point64) float64 {
+	rx, ry := b.x-a.x, b.y-a.y
+	l := rx*rx + ry*ry
+	if l == 0.0 {
+		return 0.0
+	}
+	return math.Max(0.0, math.Min(1.0, ((p.x-a.x)*rx+(p.y-a.y)*ry)/l))
+}
+
+//true if p, known to be collinear with a and b, lies within their bounds
+func on_segment64(a, b, p point64) bool {
+	return (p.x >= math.Min(a.x, b.x)) && (p.x <= math.Max(a.x, b.x)) &&
+		(p.y >= math.Min(a.y, b.y)) && (p.y <= math.Max(a.y, b.y))
+}
+
+//intersect two segments, exact end points are returned whenever an
+//end point lies on the other segment, so touching cases stay stable.
+//segments with a NaN or infinite end never intersect
+func intersect64(a1, a2, b1, b2 point64) (int, point64, point64) {
+	if !finite64(a1, a2, b1, b2) {
+		return 0, point64{}, point64{}
+	}
+	o1 := orient64(a1, a2, b1)
+	o2 := orient64(a1, a2, b2)
+	o3 := orient64(b1, b2, a1)
+	o4 := orient64(b1, b2, a2)
+	if (o1 == 0) && (o2 == 0) && (o3 == 0) && (o4 == 0) {
+		return overlap64(a1, a2, b1, b2)
+	}
+	if (o1*o2 > 0) || (o3*o4 > 0) {
+		return 0, point64{}, point64{}
+	}
+	switch {
+	case o1 == 0:
+		return 1, b1, point64{}
+	case o2 == 0:
+		return 1, b2, point64{}
+	case o3 == 0:
+		return 1, a1, point64{}
+	case o4 == 0:
+		return 1, a2, point64{}
+	}
+	rx, ry := a2.x-a1.x, a2.y-a1.y
+	sx, sy := b2.x-b1.x, b2.y-b1.y
+	qx, qy := b1.x-a1.x, b1.y-a1.y
+	t := (qx*sy - qy*sx) / (rx*sy - ry*sx)
+	t = math.Max(0.0, math.Min(1.0, t))
+	return 1, point64{a1.x + rx*t, a1.y + ry*t}, point64{}
+}
+
+//overlap of two collinear segments, possibly degenerate
+func overlap64(a1, a2, b1, b2 point64) (int, point64, point64) {
+	//order all four points along the common line
+	key := func(p point64) float64 { return p.x }
+	if math.Abs(a2.x-a1.x)+math.Abs(b2.x-b1.x) < math.Abs(a2.y-a1.y)+math.Abs(b2.y-b1.y) {
+		key = func(p point64) float64 { return p.y }
+	}
+	reversed := key(a1) > key(a2)
+	if reversed {
+		a1, a2 = a2, a1
+	}
+	if key(b1) > key(b2) {
+		b1, b2 = b2, b1
+	}
+	lo, hi := a1, a2
+	if key(b1) > key(lo) {
+		lo = b1
+	}
+	if key(b2) < key(hi) {
+		hi = b2
+	}
+	if key(lo) > key(hi) {
+		return 0, point64{}, point64{}
+	}
+	if key(lo) == key(hi) {
+		if !on_segment64(a1, a2, lo) || !on_segment64(b1, b2, lo) {
+			return 0, point64{}, point64{}
+		}
+		return 1, lo, point64{}
+	}
+	if reversed {
+		lo, hi = hi, lo
+	}
+	return 2, lo, hi
+}
+
+//closest points between two segments, see Ericson, real-time collision detection 5.1.9
+func closest64(a1, a2, b1, b2 point64) (point64, point64) {
+	if count, p, _ := intersect64(a1, a2, b1, b2); count != 0 {
+		return p, p
+	}
+	d1x, d1y := a2.x-a1.x, a2.y-a1.y
+	d2x, d2y := b2.x-b1.x, b2.y-b1.y
+	rx, ry := a1.x-b1.x, a1.y-b1.y
+	a := d1x*d1x + d1y*d1y
+	e := d2x*d2x + d2y*d2y
+	f := d2x*rx + d2y*ry
+	clamp := func(x float64) float64 { return math.Max(0.0, math.Min(1.0, x)) }
+	s, t := 0.0, 0.0
+	switch {
+	case (a == 0.0) && (e == 0.0):
+	case a == 0.0:
+		t = clamp(f / e)
+	default:
+		c := d1x*rx + d1y*ry
+		if e == 0.0 {
+			s = clamp(-c / a)
+			break
+		}
+		b := d1x*d2x + d1y*d2y
+		if denom := a*e - b*b; denom != 0.0 {
+			s = clamp((b*f - c*e) / denom)
+		}
+		t = (b*s + f) / e
+		if t < 0.0 {
+			t = 0.0
+			s = clamp(-c / a)
+		} else if t > 1.0 {
+			t = 1.0
+			s = clamp((b - c) / a)
+		}
+	}
+	return point64{a1.x + d1x*s, a1.y + d1y*s}, point64{b1.x + d2x*t, b1.y + d2y*t}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+///////
+//tests
+///////
+
+func TestIntersect_lines(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	tests := []struct {
+		name           string
+		a1, a2, b1, b2 *Point
+		count          int
+		p1, p2         *Point
+		t1, t2, u1, u2 float32
+	}{
+		{"crossing", &Point{0.0, 0.0}, &Point{10.0, 10.0}, &Point{0.0, 10.0}, &Point{10.0, 0.0},
+			1, &Point{5.0, 5.0}, nil, 0.5, 0.0, 0.5, 0.0},
+		{"parallel", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{0.0, 1.0}, &Point{10.0, 1.0},
+			0, nil, nil, 0.0, 0.0, 0.0, 0.0},
+		{"collinear overlap", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{5.0, 0.0}, &Point{15.0, 0.0},
+			2, &Point{5.0, 0.0}, &Point{10.0, 0.0}, 0.5, 1.0, 0.0, 0.5},
+		{"collinear overlap reversed", &Point{10.0, 0.0}, &Point{0.0, 0.0}, &Point{5.0, 0.0}, &Point{15.0, 0.0},
+			2, &Point{10.0, 0.0}, &Point{5.0, 0.0}, 0.0, 0.5, 0.5, 0.0},
+		{"collinear contained", &Point{0.0, 0.0}, &Point{0.0, 10.0}, &Point{0.0, 2.0}, &Point{0.0, 4.0},
+			2, &Point{0.0, 2.0}, &Point{0.0, 4.0}, 0.2, 0.4, 0.0, 1.0},
+		{"collinear end to end", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{10.0, 0.0}, &Point{20.0, 0.0},
+			1, &Point{10.0, 0.0}, nil, 1.0, 0.0, 0.0, 0.0},
+		{"collinear apart", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{11.0, 0.0}, &Point{20.0, 0.0},
+			0, nil, nil, 0.0, 0.0, 0.0, 0.0},
+		{"t junction", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{5.0, 0.0}, &Point{5.0, 5.0},
+			1, &Point{5.0, 0.0}, nil, 0.5, 0.0, 0.0, 0.0},
+		{"just short", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{5.0, 0.000001}, &Point{5.0, 5.0},
+			0, nil, nil, 0.0, 0.0, 0.0, 0.0},
+		{"just through", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{5.0, -0.000001}, &Point{5.0, 5.0},
+			1, &Point{5.0, 0.0}, nil, 0.5, 0.0, 0.0, 0.0},
+		{"degenerate point on line", &Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{3.0, 0.0}, &Point{3.0, 0.0},
+			1, &Point{3.0, 0.0}, nil, 0.3, 0.0, 0.0, 0.0},
+		{"nan", &Point{0.0, 0.0}, &Point{10.0, 10.0}, &Point{nan, 10.0}, &Point{10.0, 0.0},
+			0, nil, nil, 0.0, 0.0, 0.0, 0.0},
+		{"infinite", &Point{0.0, 0.0}, &Point{10.0, 10.0}, &Point{-inf, 5.0}, &Point{inf, 5.0},
+			0, nil, nil, 0.0, 0.0, 0.0, 0.0},
+	}
+	near := func(pp1, pp2 *Point) bool {
+		return (pp1 == nil) == (pp2 == nil) && ((pp1 == nil) || (Distance(pp1, pp2) < 0.00001))
+	}
+	for _, test := range tests {
+		i := Intersect_lines_2d(test.a1, test.a2, test.b1, test.b2)
+		if i.Count != test.count {
+			t.Errorf("%s: count %d, want %d", test.name, i.Count, test.count)
+			continue
+		}
+		if !near(i.P1, test.p1) || !near(i.P2, test.p2) {
+			t.Errorf("%s: points %v %v, want %v %v", test.name, i.P1, i.P2, test.p1, test.p2)
+		}
+		if !near(&Point{i.T1, i.T2, i.U1, i.U2}, &Point{test.t1, test.t2, test.u1, test.u2}) {
+			t.Errorf("%s: params %v %v %v %v, want %v %v %v %v", test.name,
+				i.T1, i.T2, i.U1, i.U2, test.t1, test.t2, test.u1, test.u2)
+		}
+	}
+}
+
+//the exact predicate agrees with itself however its points are ordered,
+//which float arithmetic on nearly collinear points does not
+func TestOrient_near_collinear(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	zero := 0
+	for n := 0; n < 10000; n++ {
+		s := rng.Float32()
+		x, y := 0.1+rng.Float32(), 0.1+rng.Float32()
+		a, b, c := &Point{x, y}, &Point{x + 1000.0, y + 333.3}, &Point{x + s*1000.0, y + s*333.3}
+		if n%2 == 0 {
+			//some exactly on the line
+			x, y, s = float32(rng.Intn(100)), float32(rng.Intn(100)), float32(rng.Intn(10))
+			a, b, c = &Point{x, y}, &Point{x + 1000.0, y + 300.0}, &Point{x + s*100.0, y + s*30.0}
+		}
+		o := Orient_2d(a, b, c)
+		if (Orient_2d(b, c, a) != o) || (Orient_2d(c, a, b) != o) || (Orient_2d(b, a, c) != -o) {
+			t.Fatalf("orientation of %v %v %v depends on the order", a, b, c)
+		}
+		if o == 0 {
+			zero++
+		}
+		//a segment of no length at c meets the line only when c is on it
+		if i := Intersect_lines_2d(a, b, c, c); (o == 0) != (i.Count == 1) {
+			t.Fatalf("orientation %d but %v on the line gives count %d", o, c, i.Count)
+		}
+	}
+	if zero == 0 {
+		t.Error("no exactly collinear points were made")
+	}
+}
+
+func TestOrient_non_finite(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	for _, c := range []*Point{{nan, 0.0}, {0.0, nan}, {inf, 0.0}, {0.0, -inf}} {
+		if o := Orient_2d(&Point{0.0, 0.0}, &Point{1.0, 1.0}, c); o != 0 {
+			t.Errorf("orientation of %v is %d, want 0", c, o)
+		}
+		if i := Intersect_lines_2d(&Point{0.0, 0.0}, &Point{1.0, 1.0}, c, &Point{1.0, 0.0}); i.Count != 0 {
+			t.Errorf("line to %v intersects", c)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+/////////////////////////
+//public structures/types
+/////////////////////////
+
+//2d affine transform laid out as the svg matrix(a b c d e f),
+//x' = a*x + c*y + e and y' = b*x + d*y + f
+type Matrix [6]float32
+
+//float64 transform laid out as Matrix, for placing geometry far from the origin
+type Matrix64 [6]float64
+
+//////////////////
+//public functions
+//////////////////
+
+func Identity_matrix() *Matrix {
+	return &Matrix{1.0, 0.0, 0.0, 1.0, 0.0, 0.0}
+}
+
+func Translate_matrix(tx, ty float32) *Matrix {
+	return &Matrix{1.0, 0.0, 0.0, 1.0, tx, ty}
+}
+
+//rotation in radians, y down screen space turns clockwise
+func Rotate_matrix(angle float32) *Matrix {
+	s := float32(math.Sin(float64(angle)))
+	c := float32(math.Cos(float64(angle)))
+	return &Matrix{c, s, -s, c, 0.0, 0.0}
+}
+
+//a negative scale mirrors, Scale_matrix(-1, 1) flips about the y axis
+func Scale_matrix(sx, sy float32) *Matrix {
+	return &Matrix{sx, 0.0, 0.0, sy, 0.0, 0.0}
+}
+
+//the transform that applies pm2 and then pm1
+func Mul_matrix(pm1, pm2 *Matrix) *Matrix {
+	m1, m2 := *pm1, *pm2
+	return &Matrix{
+		m1[0]*m2[0] + m1[2]*m2[1],
+		m1[1]*m2[0] + m1[3]*m2[1],
+		m1[0]*m2[2] + m1[2]*m2[3],
+		m1[1]*m2[2] + m1[3]*m2[3],
+		m1[0]*m2[4] + m1[2]*m2[5] + m1[4],
+		m1[1]*m2[4] + m1[3]*m2[5] + m1[5]}
+}
+
+//inverse transform, nil if the matrix is singular
+func Invert_matrix(pm *Matrix) *Matrix {
+	m := *pm
+	det := Matrix_determinant(pm)
+	if det == 0.0 {
+		return nil
+	}
+	return &Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det}
+}
+
+//negative if the transform mirrors
+func Matrix_determinant(pm *Matrix) float32 {
+	m := *pm
+	return m[0]*m[3] - m[1]*m[2]
+}
+
+//average scale of a transform
+func Matrix_scale(pm *Matrix) float32 {
+	return float32(math.Sqrt(math.Abs(float64(Matrix_determinant(pm)))))
+}
+
+//how far apart the transform moves the sides of a thick line from pp1 to pp2, per
+//unit of its radius. the sides stay parallel under any affine transform, so under a
+//non uniform scale this is exact for the line's length though not for its round ends.
+//Matrix_scale for a zero length line
+func Matrix_line_scale(pm *Matrix, pp1, pp2 *Point) float32 {
+	p1, p2 := *pp1, *pp2
+	return float32(Matrix64_line_scale(Matrix_to_matrix64(pm), Vec64{float64(p1[0]), float64(p1[1])}, Vec64{float64(p2[0]), float64(p2[1])}))
+}
+
+func Transform_point(pm *Matrix, pp *Point) *Point {
+	m, p := *pm, *pp
+	return &Point{m[0]*p[0] + m[2]*p[1] + m[4], m[1]*p[0] + m[3]*p[1] + m[5]}
+}
+
+//transform a direction, ignoring the translation
+func Transform_vector(pm *Matrix, pp *Point) *Point {
+	m, p := *pm, *pp
+	return &Point{m[0]*p[0] + m[2]*p[1], m[1]*p[0] + m[3]*p[1]}
+}
+
+func Transform_points(pm *Matrix, pointsp *Points) *Points {
+	points := *pointsp
+	out_points := make(Points, len(points), len(points))
+	for i, pp := range points {
+		out_points[i] = Transform_point(pm, pp)
+	}
+	return &out_points
+}
+
+////////////////////
+//float64 transforms
+////////////////////
+
+func Matrix_to_matrix64(pm *Matrix) *Matrix64 {
+	m := *pm
+	return &Matrix64{float64(m[0]), float64(m[1]), float64(m[2]), float64(m[3]), float64(m[4]), float64(m[5])}
+}
+
+func Translate_matrix64(tx, ty float64) *Matrix64 {
+	return &Matrix64{1.0, 0.0, 0.0, 1.0, tx, ty}
+}
+
+//the transform that applies pm2 and then pm1
+func Mul_matrix64(pm1, pm2 *Matrix64) *Matrix64 {
+	m1, m2 := *pm1, *pm2
+	return &Matrix64{
+		m1[0]*m2[0] + m1[2]*m2[1],
+		m1[1]*m2[0] + m1[3]*m2[1],
+		m1[0]*m2[2] + m1[2]*m2[3],
+		m1[1]*m2[2] + m1[3]*m2[3],
+		m1[0]*m2[4] + m1[2]*m2[5] + m1[4],
+		m1[1]*m2[4] + m1[3]*m2[5] + m1[5]}
+}
+
+func Matrix64_scale(pm *Matrix64) float64 {
+	m := *pm
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+//as Matrix_line_scale
+func Matrix64_line_scale(pm *Matrix64, v1, v2 Vec64) float64 {
+	m := *pm
+	dx, dy := v2.X-v1.X, v2.Y-v1.Y
+	tx, ty := m[0]*dx+m[2]*dy, m[1]*dx+m[3]*dy
+	tl := math.Sqrt(tx*tx + ty*ty)
+	if (tl == 0.0) || ((dx == 0.0) && (dy == 0.0)) {
+		return Matrix64_scale(pm)
+	}
+	return math.Abs(m[0]*m[3]-m[1]*m[2]) * math.Sqrt(dx*dx+dy*dy) / tl
+}
+
+func Transform_vec64(pm *Matrix64, v Vec64) Vec64 {
+	m := *pm
+	return Vec64{m[0]*v.X + m[2]*v.Y + m[4], m[1]*v.X + m[3]*v.Y + m[5]}
+}
@@ -16,6 +16,24 @@ import (
 type Point []float32
 type Points []*Point
 
+//mitre limits for the _ex thickening functions, the default bevels joins
+//sharper than about 29 degrees, no limit mitres every join as the originals do
+const (
+	Default_mitre_limit = 4.0
+	No_mitre_limit      = math.MaxFloat32
+)
+
+//contact between two thick lines, Distance is the gap between the outlines
+//and goes negative as they overlap, Depth is the overlap and Mtv the
+//minimum translation that moves the first line clear of the second
+type Contact struct {
+	P1       *Point
+	P2       *Point
+	Distance float32
+	Depth    float32
+	Mtv      *Point
+}
+
 //////////////////
 //public functions
 //////////////////
@@ -376,67 +394,31 @@ func Distance_squared_to_line_3d(pp, pp1, pp2 *Point) float32 {
 }
 
 func Collide_lines_2d(pl1_p1, pl1_p2, pl2_p1, pl2_p2 *Point) bool {
-	l1_p1 := *pl1_p1
-	l1_p2 := *pl1_p2
-	l2_p1 := *pl2_p1
-	l2_p2 := *pl2_p2
-	l1_x1, l1_y1 := l1_p1[0], l1_p1[1]
-	l1_x2, l1_y2 := l1_p2[0], l1_p2[1]
-	l2_x1, l2_y1 := l2_p1[0], l2_p1[1]
-	l2_x2, l2_y2 := l2_p2[0], l2_p2[1]
-	ax := l1_x2 - l1_x1
-	ay := l1_y2 - l1_y1
-	bx := l2_x1 - l2_x2
-	by := l2_y1 - l2_y2
-	cx := l1_x1 - l2_x1
-	cy := l1_y1 - l2_y1
-	an := by*cx - bx*cy
-	ad := ay*bx - ax*by
-	bn := ax*cy - ay*cx
-	bd := ay*bx - ax*by
-	if (ad == 0) || (bd == 0) {
-		return false
-	} else {
-		if ad > 0 {
-			if (an < 0) || (an > ad) {
-				return false
-			}
-		} else {
-			if (an > 0) || (an < ad) {
-				return false
-			}
-		}
-		if bd > 0 {
-			if (bn < 0) || (bn > bd) {
-				return false
-			}
-		} else {
-			if (bn > 0) || (bn < bd) {
-				return false
-			}
-		}
-	}
-	return true
+	return Collide_lines_vec2(Point_to_vec2(pl1_p1), Point_to_vec2(pl1_p2), Point_to_vec2(pl2_p1), Point_to_vec2(pl2_p2))
 }
 
 func Collide_thick_lines_2d(tl1_p1, tl1_p2, tl2_p1, tl2_p2 *Point, r float32) bool {
-	if Collide_lines_2d(tl1_p1, tl1_p2, tl2_p1, tl2_p2) {
-		return true
-	}
-	r *= r
-	if Distance_squared_to_line_2d(tl2_p1, tl1_p1, tl1_p2) <= r {
-		return true
-	}
-	if Distance_squared_to_line_2d(tl2_p2, tl1_p1, tl1_p2) <= r {
-		return true
-	}
-	if Distance_squared_to_line_2d(tl1_p1, tl2_p1, tl2_p2) <= r {
-		return true
-	}
-	if Distance_squared_to_line_2d(tl1_p2, tl2_p1, tl2_p2) <= r {
-		return true
-	}
-	return false
+	return Collide_thick_lines_vec2(Point_to_vec2(tl1_p1), Point_to_vec2(tl1_p2), Point_to_vec2(tl2_p1), Point_to_vec2(tl2_p2), r)
+}
+
+//closest points between two line segments
+func Closest_points_lines_2d(pl1_p1, pl1_p2, pl2_p1, pl2_p2 *Point) (*Point, *Point) {
+	l1_p1, l1_p2, l2_p1, l2_p2 := *pl1_p1, *pl1_p2, *pl2_p1, *pl2_p2
+	a1 := point64{float64(l1_p1[0]), float64(l1_p1[1])}
+	a2 := point64{float64(l1_p2[0]), float64(l1_p2[1])}
+	b1 := point64{float64(l2_p1[0]), float64(l2_p1[1])}
+	b2 := point64{float64(l2_p2[0]), float64(l2_p2[1])}
+	c1, c2 := closest64(a1, a2, b1, b2)
+	return &Point{float32(c1.x), float32(c1.y)}, &Point{float32(c2.x), float32(c2.y)}
+}
+
+func Contact_thick_lines_2d(tl1_p1, tl1_p2, tl2_p1, tl2_p2 *Point, r float32) *Contact {
+	l1_p1, l1_p2, l2_p1, l2_p2 := *tl1_p1, *tl1_p2, *tl2_p1, *tl2_p2
+	a1 := point64{float64(l1_p1[0]), float64(l1_p1[1])}
+	a2 := point64{float64(l1_p2[0]), float64(l1_p2[1])}
+	b1 := point64{float64(l2_p1[0]), float64(l2_p1[1])}
+	b2 := point64{float64(l2_p2[0]), float64(l2_p2[1])}
+	return contact64(a1, a2, b1, b2, float64(r))
 }
 
 ////////////////////
@@ -476,197 +458,201 @@ func Circle_as_tristrip(p *Point, radius1, radius2 float32, resolution int) *Poi
 }
 
 func Thicken_path_as_lines(pathp *Points, radius float32, capstyle, joinstyle, resolution int) *Points {
-	if radius == 0.0 {
-		radius = 0.00000001
+	return Thicken_path_as_lines_ex(pathp, radius, capstyle, joinstyle, resolution, No_mitre_limit)
+}
+
+func Thicken_path_as_tristrip(pathp *Points, radius float32, capstyle, joinstyle, resolution int) *Points {
+	return Thicken_path_as_tristrip_ex(pathp, radius, capstyle, joinstyle, resolution, No_mitre_limit)
+}
+
+//mitre_limit is the longest mitre allowed as a multiple of the radius, sharper joins are bevelled
+func Thicken_path_as_lines_ex(pathp *Points, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_path_as_lines_vec2(Points_to_vec2s(pathp), radius, capstyle, joinstyle, resolution, mitre_limit))
+}
+
+func Thicken_path_as_tristrip_ex(pathp *Points, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_path_as_tristrip_vec2(Points_to_vec2s(pathp), radius, capstyle, joinstyle, resolution, mitre_limit))
+}
+
+//closed paths join the last point back to the first and have no caps, the outline
+//runs round one side and then the other, joined by a line across the stroke.
+//paths of fewer than three distinct points are thickened open with butt caps
+func Thicken_closed_path_as_lines(pathp *Points, radius float32, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_closed_path_as_lines_vec2(Points_to_vec2s(pathp), radius, joinstyle, resolution, mitre_limit))
+}
+
+func Thicken_closed_path_as_tristrip(pathp *Points, radius float32, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_closed_path_as_tristrip_vec2(Points_to_vec2s(pathp), radius, joinstyle, resolution, mitre_limit))
+}
+
+//variable width versions take a radius for every path point, the outline blends
+//linearly from one point's radius to the next. radii are fitted to the path with Fit_radii
+func Thicken_variable_path_as_lines(pathp *Points, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_variable_path_as_lines_vec2(Points_to_vec2s(pathp), radii, capstyle, joinstyle, resolution, mitre_limit))
+}
+
+func Thicken_variable_path_as_tristrip(pathp *Points, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_variable_path_as_tristrip_vec2(Points_to_vec2s(pathp), radii, capstyle, joinstyle, resolution, mitre_limit))
+}
+
+func Thicken_variable_closed_path_as_lines(pathp *Points, radii []float32, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_variable_closed_path_as_lines_vec2(Points_to_vec2s(pathp), radii, joinstyle, resolution, mitre_limit))
+}
+
+func Thicken_variable_closed_path_as_tristrip(pathp *Points, radii []float32, joinstyle, resolution int, mitre_limit float32) *Points {
+	return Vec2s_to_points(Thicken_variable_closed_path_as_tristrip_vec2(Points_to_vec2s(pathp), radii, joinstyle, resolution, mitre_limit))
+}
+
+//radius for every path point from a function of the distance along the path
+func Path_radii(pathp *Points, width func(s, length float32) float32) []float32 {
+	path := *pathp
+	dists := make([]float32, len(path), len(path))
+	for i := 1; i < len(path); i++ {
+		dists[i] = dists[i-1] + Distance_2d(path[i-1], path[i])
 	}
+	radii := make([]float32, len(path), len(path))
+	for i := range path {
+		radii[i] = width(dists[i], dists[len(dists)-1])
+	}
+	return radii
+}
+
+//radii cut or stretched to n points, missing ones repeat the last given or are 0
+//if none are, so a radii slice of the wrong length still gives one per point
+func Fit_radii(radii []float32, n int) []float32 {
+	if len(radii) == n {
+		return radii
+	}
+	out := make([]float32, n, n)
+	last := float32(0.0)
+	for i := range out {
+		if i < len(radii) {
+			last = radii[i]
+		}
+		out[i] = last
+	}
+	return out
+}
+
+//split path segments so no segment is longer than max_length,
+//gives a width function more points to follow
+func Subdivide_path(pathp *Points, max_length float32) *Points {
 	path := *pathp
-	index := 0
-	step := 1
 	out_points := Points{}
-	for {
-		p1 := path[index]
-		index += step
-		p2 := path[index]
-		index += step
-		l2_v := Sub_2d(p2, p1)
-		l2_pv := Perp_2d(l2_v)
-		l2_npv := Norm_2d(l2_pv)
-		rv := Scale_2d(l2_npv, radius)
-		switch {
-		case capstyle == 0:
-			//butt cap
-			out_points = append(out_points, Sub_2d(p1, rv))
-			out_points = append(out_points, Add_2d(p1, rv))
-		case capstyle == 1:
-			//square cap
-			p0 := Add_2d(p1, Perp_2d(rv))
-			out_points = append(out_points, Sub_2d(p0, rv))
-			out_points = append(out_points, Add_2d(p0, rv))
-		case capstyle == 2:
-			//triangle cap
-			out_points = append(out_points, Sub_2d(p1, rv))
-			out_points = append(out_points, Add_2d(p1, Perp_2d(rv)))
-			out_points = append(out_points, Add_2d(p1, rv))
-		default:
-			//round cap
-			rvd := *rv
-			rvx, rvy := rvd[0], rvd[1]
-			for i := 0; i <= resolution; i++ {
-				angle := float64((float32(i) * math.Pi) / float32(resolution))
-				s := float32(math.Sin(angle))
-				c := float32(math.Cos(angle))
-				rv := &Point{rvx*c - rvy*s, rvx*s + rvy*c}
-				out_points = append(out_points, Sub_2d(p1, rv))
-			}
-		}
-		for (index != -1) && (index != len(path)) {
-			p1, l1_v, l1_npv := p2, l2_v, l2_npv
-			p2 = path[index]
-			index += step
-			l2_v = Sub_2d(p2, p1)
-			l2_pv = Perp_2d(l2_v)
-			l2_npv = Norm_2d(l2_pv)
-			nbv := Norm_2d(Scale_2d(Add_2d(l1_npv, l2_npv), 0.5))
-			c := Dot_2d(nbv, Norm_2d(l1_v))
-			switch {
-			case (c <= 0) || (joinstyle == 0):
-				//mitre join
-				s := float32(math.Sin(math.Acos(float64(c))))
-				bv := Scale_2d(nbv, radius/s)
-				out_points = append(out_points, Add_2d(p1, bv))
-			case joinstyle == 1:
-				//bevel join
-				out_points = append(out_points, Add_2d(p1, Scale_2d(l1_npv, radius)))
-				out_points = append(out_points, Add_2d(p1, Scale_2d(l2_npv, radius)))
-			default:
-				//round join
-				rv := Scale_2d(l1_npv, radius)
-				rvd := *rv
-				rvx, rvy := rvd[0], rvd[1]
-				theta := float32(math.Acos(float64(Dot_2d(l1_npv, l2_npv))))
-				segs := int((theta/float32(math.Pi))*float32(resolution)) + 1
-				for i := 0; i <= segs; i++ {
-					angle := float64((float32(i) * theta) / float32(segs))
-					s := float32(math.Sin(angle))
-					c := float32(math.Cos(angle))
-					rv := &Point{rvx*c - rvy*s, rvx*s + rvy*c}
-					out_points = append(out_points, Add_2d(p1, rv))
-				}
+	for i, p := range path {
+		if i != 0 {
+			p0 := path[i-1]
+			segs := int(math.Ceil(float64(Distance_2d(p0, p) / max_length)))
+			v := Sub_2d(p, p0)
+			for j := 1; j < segs; j++ {
+				out_points = append(out_points, Add_2d(p0, Scale_2d(v, float32(j)/float32(segs))))
 			}
 		}
-		if step < 0 {
-			break
-		}
-		step = -step
-		index += step
+		out_points = append(out_points, p)
 	}
-	out_points = append(out_points, out_points[0])
 	return &out_points
 }
 
-func Thicken_path_as_tristrip(pathp *Points, radius float32, capstyle, joinstyle, resolution int) *Points {
-	if radius == 0.0 {
-		radius = 0.00000001
-	}
+//collide two thick lines whose radii vary linearly from one end to the other
+func Collide_tapered_lines_2d(tl1_p1, tl1_p2 *Point, tl1_r1, tl1_r2 float32, tl2_p1, tl2_p2 *Point, tl2_r1, tl2_r2 float32) bool {
+	return Collide_tapered_lines_vec2(Point_to_vec2(tl1_p1), Point_to_vec2(tl1_p2), tl1_r1, tl1_r2,
+		Point_to_vec2(tl2_p1), Point_to_vec2(tl2_p2), tl2_r1, tl2_r2)
+}
+
+//split a path into dashes, pattern alternates dash and gap lengths, odd length
+//patterns repeat twice, phase is the distance into the pattern the path starts at.
+//dashes carry on round corners, zero length dashes come out as a point twice for
+//the caps to draw. a pattern with a negative or non finite length, or adding up to
+//nothing, is rejected and the path comes back whole, as SVG strokes it solid
+func Dash_path(pathp *Points, pattern []float32, phase float32) []*Points {
 	path := *pathp
-	index := 0
-	step := 1
-	out_points := Points{}
-	for {
-		p1 := path[index]
-		index += step
-		p2 := path[index]
-		index += step
-		l2_v := Sub_2d(p2, p1)
-		l2_pv := Perp_2d(l2_v)
-		l2_npv := Norm_2d(l2_pv)
-		rv := Scale_2d(l2_npv, radius)
-		switch {
-		case capstyle == 0:
-			//butt cap
-			out_points = append(out_points, p1)
-			out_points = append(out_points, Sub_2d(p1, rv))
-			out_points = append(out_points, p1)
-			out_points = append(out_points, Add_2d(p1, rv))
-		case capstyle == 1:
-			//square cap
-			p0 := Add_2d(p1, Perp_2d(rv))
-			out_points = append(out_points, p0)
-			out_points = append(out_points, Sub_2d(p0, rv))
-			out_points = append(out_points, p0)
-			out_points = append(out_points, Add_2d(p0, rv))
-		case capstyle == 2:
-			//triangle cap
-			out_points = append(out_points, p1)
-			out_points = append(out_points, Sub_2d(p1, rv))
-			out_points = append(out_points, p1)
-			out_points = append(out_points, Add_2d(p1, Perp_2d(rv)))
-			out_points = append(out_points, p1)
-			out_points = append(out_points, Add_2d(p1, rv))
-		default:
-			//round cap
-			rvd := *rv
-			rvx, rvy := rvd[0], rvd[1]
-			for i := 0; i <= resolution; i++ {
-				angle := float64((float32(i) * math.Pi) / float32(resolution))
-				s := float32(math.Sin(angle))
-				c := float32(math.Cos(angle))
-				rv := &Point{rvx*c - rvy*s, rvx*s + rvy*c}
-				out_points = append(out_points, p1)
-				out_points = append(out_points, Sub_2d(p1, rv))
-			}
+	if len(pattern)%2 != 0 {
+		pattern = append(append([]float32{}, pattern...), pattern...)
+	}
+	total := float32(0.0)
+	for _, l := range pattern {
+		if !(l >= 0.0) || math.IsInf(float64(l), 1) {
+			return []*Points{pathp}
 		}
-		for (index != -1) && (index != len(path)) {
-			p1, l1_v, l1_npv := p2, l2_v, l2_npv
-			p2 = path[index]
-			index += step
-			l2_v = Sub_2d(p2, p1)
-			l2_pv = Perp_2d(l2_v)
-			l2_npv = Norm_2d(l2_pv)
-			nbv := Norm_2d(Scale_2d(Add_2d(l1_npv, l2_npv), 0.5))
-			c := Dot_2d(nbv, Norm_2d(l1_v))
-			switch {
-			case (c <= 0) || (joinstyle == 0):
-				//mitre join
-				s := float32(math.Sin(math.Acos(float64(c))))
-				bv := Scale_2d(nbv, radius/s)
-				out_points = append(out_points, p1)
-				out_points = append(out_points, Add_2d(p1, bv))
-			case joinstyle == 1:
-				//bevel join
-				out_points = append(out_points, p1)
-				out_points = append(out_points, Add_2d(p1, Scale_2d(l1_npv, radius)))
-				out_points = append(out_points, p1)
-				out_points = append(out_points, Add_2d(p1, Scale_2d(l2_npv, radius)))
-			default:
-				//round join
-				rv := Scale_2d(l1_npv, radius)
-				rvd := *rv
-				rvx, rvy := rvd[0], rvd[1]
-				theta := float32(math.Acos(float64(Dot_2d(l1_npv, l2_npv))))
-				segs := int((theta/float32(math.Pi))*float32(resolution)) + 1
-				for i := 0; i <= segs; i++ {
-					angle := float64((float32(i) * theta) / float32(segs))
-					s := float32(math.Sin(angle))
-					c := float32(math.Cos(angle))
-					rv := &Point{rvx*c - rvy*s, rvx*s + rvy*c}
-					out_points = append(out_points, p1)
-					out_points = append(out_points, Add_2d(p1, rv))
-				}
+		total += l
+	}
+	if (len(path) < 2) || (total <= 0.0) {
+		return []*Points{pathp}
+	}
+	//skip into the pattern by the phase
+	i := 0
+	on := true
+	remaining := pattern[0]
+	phase = float32(math.Mod(float64(phase), float64(total)))
+	if phase < 0.0 {
+		phase += total
+	}
+	for phase > 0.0 {
+		if phase < remaining {
+			remaining -= phase
+			break
+		}
+		phase -= remaining
+		i = (i + 1) % len(pattern)
+		on = !on
+		remaining = pattern[i]
+	}
+	dashes := []*Points{}
+	dash := Points{}
+	if on {
+		dash = append(dash, path[0])
+	}
+	for j := 1; j < len(path); j++ {
+		p0, p1 := path[j-1], path[j]
+		l := Distance_2d(p0, p1)
+		if l == 0.0 {
+			continue
+		}
+		v := Scale_2d(Sub_2d(p1, p0), 1.0/l)
+		pos := float32(0.0)
+		for l-pos >= remaining {
+			pos += remaining
+			p := Add_2d(p0, Scale_2d(v, pos))
+			if on {
+				dash = append(dash, p)
+				done := dash
+				dashes = append(dashes, &done)
+				dash = Points{}
+			} else {
+				dash = Points{p}
 			}
+			on = !on
+			i = (i + 1) % len(pattern)
+			remaining = pattern[i]
 		}
-		if step < 0 {
-			break
+		remaining -= l - pos
+		if on && !Equal_2d(dash[len(dash)-1], p1) {
+			dash = append(dash, p1)
 		}
-		step = -step
-		index += step
 	}
-	out_points = append(out_points, out_points[0])
-	out_points = append(out_points, out_points[1])
+	if on && (len(dash) > 1) {
+		dashes = append(dashes, &dash)
+	}
+	return dashes
+}
+
+//join triangle strips into one with degenerate triangles between them
+func Join_tristrips(strips []*Points) *Points {
+	out_points := Points{}
+	for _, stripp := range strips {
+		strip := *stripp
+		if len(strip) == 0 {
+			continue
+		}
+		if len(out_points) != 0 {
+			out_points = append(out_points, out_points[len(out_points)-1], strip[0])
+		}
+		out_points = append(out_points, strip...)
+	}
 	return &out_points
 }
 
-func recursive_bezier(x1, y1, x2, y2, x3, y3, x4, y4 float32, pointsp *Points, distance_tolerance float32) *Points {
+func recursive_bezier(x1, y1, x2, y2, x3, y3, x4, y4 float32, points Vec2s, distance_tolerance float32) Vec2s {
 	//calculate all the mid-points of the line segments
 	x12 := (x1 + x2) * 0.5
 	y12 := (y1 + y2) * 0.5
@@ -688,24 +674,493 @@ func recursive_bezier(x1, y1, x2, y2, x3, y3, x4, y4 float32, pointsp *Points, d
 	d2 := float32(math.Abs(float64(((x2-x4)*dy - (y2-y4)*dx))))
 	d3 := float32(math.Abs(float64(((x3-x4)*dy - (y3-y4)*dx))))
 
-	points := *pointsp
 	if (d2+d3)*(d2+d3) < distance_tolerance*(dx*dx+dy*dy) {
-		points = append(points, &Point{x1234, y1234})
-		return &points
+		return append(points, Vec2{x1234, y1234})
 	}
 
 	//continue subdivision
-	points = *recursive_bezier(x1, y1, x12, y12, x123, y123, x1234, y1234, &points, distance_tolerance)
-	points = *recursive_bezier(x1234, y1234, x234, y234, x34, y34, x4, y4, &points, distance_tolerance)
-	return &points
+	points = recursive_bezier(x1, y1, x12, y12, x123, y123, x1234, y1234, points, distance_tolerance)
+	return recursive_bezier(x1234, y1234, x234, y234, x34, y34, x4, y4, points, distance_tolerance)
 }
 
 //create bezier path
 func Bezier_path_as_lines(pp1, pp2, pp3, pp4 *Point, distance_tolerance float32) *Points {
-	p1, p2, p3, p4 := *pp1, *pp2, *pp3, *pp4
+	return Vec2s_to_points(Bezier_path_as_lines_vec2(Point_to_vec2(pp1), Point_to_vec2(pp2), Point_to_vec2(pp3), Point_to_vec2(pp4), distance_tolerance))
+}
+
+func recursive_quadratic(x1, y1, x2, y2, x3, y3 float32, pointsp *Points, distance_tolerance float32) *Points {
+	//calculate all the mid-points of the line segments
+	x12 := (x1 + x2) * 0.5
+	y12 := (y1 + y2) * 0.5
+	x23 := (x2 + x3) * 0.5
+	y23 := (y2 + y3) * 0.5
+	x123 := (x12 + x23) * 0.5
+	y123 := (y12 + y23) * 0.5
+
+	//try to approximate the full quadratic curve by a single straight line
+	dx := x3 - x1
+	dy := y3 - y1
+
+	d := float32(math.Abs(float64(((x2-x3)*dy - (y2-y3)*dx))))
+
+	points := *pointsp
+	if d*d < distance_tolerance*(dx*dx+dy*dy) {
+		points = append(points, &Point{x123, y123})
+		return &points
+	}
+
+	//continue subdivision
+	points = *recursive_quadratic(x1, y1, x12, y12, x123, y123, &points, distance_tolerance)
+	points = *recursive_quadratic(x123, y123, x23, y23, x3, y3, &points, distance_tolerance)
+	return &points
+}
+
+//create quadratic bezier path
+func Quadratic_path_as_lines(pp1, pp2, pp3 *Point, distance_tolerance float32) *Points {
+	p1, p2, p3 := *pp1, *pp2, *pp3
 	points := Points{}
 	points = append(points, &Point{p1[0], p1[1]})
-	points = *recursive_bezier(p1[0], p1[1], p2[0], p2[1], p3[0], p3[1], p4[0], p4[1], &points, distance_tolerance)
-	points = append(points, &Point{p4[0], p4[1]})
+	points = *recursive_quadratic(p1[0], p1[1], p2[0], p2[1], p3[0], p3[1], &points, distance_tolerance)
+	points = append(points, &Point{p3[0], p3[1]})
 	return &points
 }
+
+//centripetal catmull rom spline through all the points as bezier control points,
+//3n+1 of them as from Fit_beziers, the end spans use mirrored neighbours
+func Catmull_rom_as_beziers(pathp *Points) *Points {
+	path := spline_points(pathp)
+	ctrl := Points{}
+	if len(path) == 0 {
+		return &ctrl
+	}
+	ctrl = append(ctrl, &Point{(*path[0])[0], (*path[0])[1]})
+	for i := 0; i+1 < len(path); i++ {
+		p1, p2 := path[i], path[i+1]
+		p0 := Sub_2d(Scale_2d(p1, 2.0), p2)
+		if i != 0 {
+			p0 = path[i-1]
+		}
+		p3 := Sub_2d(Scale_2d(p2, 2.0), p1)
+		if i+2 < len(path) {
+			p3 = path[i+2]
+		}
+		//knot intervals, square root of the chord lengths
+		d0 := float32(math.Sqrt(float64(Distance_2d(p0, p1))))
+		d1 := float32(math.Sqrt(float64(Distance_2d(p1, p2))))
+		d2 := float32(math.Sqrt(float64(Distance_2d(p2, p3))))
+		if d0 == 0.0 {
+			d0 = d1
+		}
+		if d2 == 0.0 {
+			d2 = d1
+		}
+		//tangents scaled to this span
+		m1 := Scale_2d(Add_2d(Sub_2d(Scale_2d(Sub_2d(p1, p0), 1.0/d0), Scale_2d(Sub_2d(p2, p0), 1.0/(d0+d1))),
+			Scale_2d(Sub_2d(p2, p1), 1.0/d1)), d1)
+		m2 := Scale_2d(Add_2d(Sub_2d(Scale_2d(Sub_2d(p2, p1), 1.0/d1), Scale_2d(Sub_2d(p3, p1), 1.0/(d1+d2))),
+			Scale_2d(Sub_2d(p3, p2), 1.0/d2)), d1)
+		ctrl = append(ctrl, Add_2d(p1, Scale_2d(m1, 1.0/3.0)), Sub_2d(p2, Scale_2d(m2, 1.0/3.0)), &Point{(*p2)[0], (*p2)[1]})
+	}
+	return &ctrl
+}
+
+//create centripetal catmull rom path through all the points
+func Catmull_rom_path_as_lines(pathp *Points, distance_tolerance float32) *Points {
+	return Beziers_as_lines(Catmull_rom_as_beziers(pathp), distance_tolerance)
+}
+
+//uniform cubic b-spline using the points as control points, as bezier control
+//points, the end points are repeated so the curve starts and ends on them
+func Bspline_as_beziers(pathp *Points) *Points {
+	path := spline_points(pathp)
+	ctrl := Points{}
+	if len(path) == 0 {
+		return &ctrl
+	}
+	ctrl = append(ctrl, &Point{(*path[0])[0], (*path[0])[1]})
+	if len(path) == 1 {
+		return &ctrl
+	}
+	path = append(Points{path[0], path[0]}, path...)
+	path = append(path, path[len(path)-1], path[len(path)-1])
+	for i := 0; i+3 < len(path); i++ {
+		q1, q2, q3 := path[i+1], path[i+2], path[i+3]
+		c1 := Scale_2d(Add_2d(Scale_2d(q1, 2.0), q2), 1.0/3.0)
+		c2 := Scale_2d(Add_2d(q1, Scale_2d(q2, 2.0)), 1.0/3.0)
+		b2 := Scale_2d(Add_2d(Add_2d(q1, Scale_2d(q2, 4.0)), q3), 1.0/6.0)
+		ctrl = append(ctrl, c1, c2, b2)
+	}
+	return &ctrl
+}
+
+//create uniform cubic b-spline path using the points as control points
+func Bspline_path_as_lines(pathp *Points, distance_tolerance float32) *Points {
+	return Beziers_as_lines(Bspline_as_beziers(pathp), distance_tolerance)
+}
+
+//create elliptical arc path, angles in radians, sweep is signed
+func Arc_as_lines(pp *Point, rx, ry, rotation, start, sweep, distance_tolerance float32) *Points {
+	p := *pp
+	rx = float32(math.Abs(float64(rx)))
+	ry = float32(math.Abs(float64(ry)))
+	//pick the step angle that keeps the chord sagitta within tolerance
+	r := float64(rx)
+	if ry > rx {
+		r = float64(ry)
+	}
+	step := math.Pi * 0.5
+	if d := math.Sqrt(float64(distance_tolerance)); d < r {
+		step = math.Min(step, 2.0*math.Acos(1.0-d/r))
+	}
+	segs := int(math.Ceil(math.Abs(float64(sweep)) / step))
+	if segs < 1 {
+		segs = 1
+	}
+	sr := math.Sin(float64(rotation))
+	cr := math.Cos(float64(rotation))
+	points := make(Points, segs+1, segs+1)
+	for i := 0; i <= segs; i++ {
+		angle := float64(start) + (float64(sweep)*float64(i))/float64(segs)
+		x := float64(rx) * math.Cos(angle)
+		y := float64(ry) * math.Sin(angle)
+		points[i] = &Point{p[0] + float32(x*cr-y*sr), p[1] + float32(x*sr+y*cr)}
+	}
+	return &points
+}
+
+//center parameterization of an svg style endpoint arc, see svg spec F.6.5.
+//radii too small to reach are scaled up, the points must differ and the radii be non zero
+func Svg_arc_center(pp1, pp2 *Point, rx, ry, rotation float32, large_arc, sweep bool) (*Point, float32, float32, float32, float32) {
+	p1, p2 := *pp1, *pp2
+	frx := math.Abs(float64(rx))
+	fry := math.Abs(float64(ry))
+	sr := math.Sin(float64(rotation))
+	cr := math.Cos(float64(rotation))
+	hx := float64(p1[0]-p2[0]) * 0.5
+	hy := float64(p1[1]-p2[1]) * 0.5
+	x1 := cr*hx + sr*hy
+	y1 := -sr*hx + cr*hy
+	l := (x1*x1)/(frx*frx) + (y1*y1)/(fry*fry)
+	if l > 1.0 {
+		l = math.Sqrt(l)
+		frx *= l
+		fry *= l
+	}
+	num := frx*frx*fry*fry - frx*frx*y1*y1 - fry*fry*x1*x1
+	den := frx*frx*y1*y1 + fry*fry*x1*x1
+	coef := math.Sqrt(math.Max(0.0, num/den))
+	if large_arc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * frx * y1 / fry
+	cy1 := -coef * fry * x1 / frx
+	cx := cr*cx1 - sr*cy1 + float64(p1[0]+p2[0])*0.5
+	cy := sr*cx1 + cr*cy1 + float64(p1[1]+p2[1])*0.5
+	theta := math.Atan2((y1-cy1)/fry, (x1-cx1)/frx)
+	delta := math.Atan2((-y1-cy1)/fry, (-x1-cx1)/frx) - theta
+	if sweep && (delta < 0.0) {
+		delta += math.Pi * 2.0
+	} else if !sweep && (delta > 0.0) {
+		delta -= math.Pi * 2.0
+	}
+	return &Point{float32(cx), float32(cy)}, float32(frx), float32(fry), float32(theta), float32(delta)
+}
+
+//create svg style endpoint arc path, rotation in radians
+func Svg_arc_as_lines(pp1, pp2 *Point, rx, ry, rotation float32, large_arc, sweep bool, distance_tolerance float32) *Points {
+	p1, p2 := *pp1, *pp2
+	if Equal_2d(pp1, pp2) {
+		return &Points{&Point{p1[0], p1[1]}}
+	}
+	if (rx == 0.0) || (ry == 0.0) {
+		return &Points{&Point{p1[0], p1[1]}, &Point{p2[0], p2[1]}}
+	}
+	center, frx, fry, theta, delta := Svg_arc_center(pp1, pp2, rx, ry, rotation, large_arc, sweep)
+	pointsp := Arc_as_lines(center, frx, fry, rotation, theta, delta, distance_tolerance)
+	points := *pointsp
+	points[0] = &Point{p1[0], p1[1]}
+	points[len(points)-1] = &Point{p2[0], p2[1]}
+	return pointsp
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//spline input with repeated points removed, they have no chord to follow
+func spline_points(pathp *Points) Points {
+	path := Points{}
+	for _, p := range *pathp {
+		if (len(path) == 0) || !Equal_2d(path[len(path)-1], p) {
+			path = append(path, p)
+		}
+	}
+	return path
+}
+
+//unit normal of a segment, zero length segments get an arbitrary one
+func segment_normal(p1, p2 Vec2) Vec2 {
+	if p1 == p2 {
+		return Vec2{0.0, -1.0}
+	}
+	return Norm_vec2(Perp_vec2(Sub_vec2(p2, p1)))
+}
+
+//same radius for every path point
+func path_radius(n int, radius float32) []float32 {
+	radii := make([]float32, n, n)
+	for i := range radii {
+		radii[i] = radius
+	}
+	return radii
+}
+
+//drop repeated points, they have no direction to thicken along,
+//radii must be positive so they are clamped to a tiny minimum
+func unique_path(points Vec2s, radii []float32) (Vec2s, []float32) {
+	path := Vec2s{}
+	path_radii := []float32{}
+	for i, p := range points {
+		if (len(path) == 0) || (path[len(path)-1] != p) {
+			radius := radii[i]
+			if radius <= 0.0 {
+				radius = 0.00000001
+			}
+			path = append(path, p)
+			path_radii = append(path_radii, radius)
+		}
+	}
+	if len(path) == 1 {
+		path = append(path, path[0])
+		path_radii = append(path_radii, path_radii[0])
+	}
+	return path, path_radii
+}
+
+//loops of centre and outline point pairs, one for an open path and two for a closed one
+func thicken_loops(points Vec2s, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32, closed bool) []Vec2s {
+	if mitre_limit < 1.0 {
+		mitre_limit = 1.0
+	}
+	path, radii := unique_path(points, Fit_radii(radii, len(points)))
+	if closed {
+		if path[0] == path[len(path)-1] {
+			path, radii = path[:len(path)-1], radii[:len(radii)-1]
+		}
+		if len(path) > 2 {
+			side1 := thicken_closed_path(path, radii, joinstyle, resolution, mitre_limit)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+				radii[i], radii[j] = radii[j], radii[i]
+			}
+			side2 := thicken_closed_path(path, radii, joinstyle, resolution, mitre_limit)
+			return []Vec2s{side1, side2}
+		}
+	}
+	return []Vec2s{thicken_path(path, radii, capstyle, joinstyle, resolution, mitre_limit)}
+}
+
+//walk once round a closed path joining every vertex, path must not repeat its first point
+func thicken_closed_path(path Vec2s, radii []float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	out_points := Vec2s{}
+	p0 := path[len(path)-1]
+	for i, p1 := range path {
+		p2 := path[(i+1)%len(path)]
+		l1_v := Sub_vec2(p1, p0)
+		out_points = thicken_join(out_points, p1, l1_v, segment_normal(p0, p1), segment_normal(p1, p2), radii[i], joinstyle, resolution, mitre_limit)
+		p0 = p1
+	}
+	return out_points
+}
+
+//walk out along one side of the path and back along the other,
+//emitting pairs of centre and outline points
+func thicken_path(path Vec2s, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	index := 0
+	step := 1
+	out_points := Vec2s{}
+	for {
+		p1 := path[index]
+		radius := radii[index]
+		index += step
+		p2 := path[index]
+		index += step
+		l2_v := Sub_vec2(p2, p1)
+		l2_npv := segment_normal(p1, p2)
+		if (p1 == p2) && (step < 0) {
+			//a lone point, the way back faces the other way so the caps meet round it
+			l2_npv = Scale_vec2(l2_npv, -1.0)
+		}
+		rv := Scale_vec2(l2_npv, radius)
+		switch {
+		case capstyle == 0:
+			//butt cap
+			out_points = append(out_points, p1, Sub_vec2(p1, rv))
+			out_points = append(out_points, p1, Add_vec2(p1, rv))
+		case capstyle == 1:
+			//square cap
+			p0 := Add_vec2(p1, Perp_vec2(rv))
+			out_points = append(out_points, p0, Sub_vec2(p0, rv))
+			out_points = append(out_points, p0, Add_vec2(p0, rv))
+		case capstyle == 2:
+			//triangle cap
+			out_points = append(out_points, p1, Sub_vec2(p1, rv))
+			out_points = append(out_points, p1, Add_vec2(p1, Perp_vec2(rv)))
+			out_points = append(out_points, p1, Add_vec2(p1, rv))
+		default:
+			//round cap
+			out_points = thicken_arc(out_points, p1, Scale_vec2(rv, -1.0), math.Pi, resolution)
+		}
+		for (index != -1) && (index != len(path)) {
+			p1, l1_v, l1_npv := p2, l2_v, l2_npv
+			radius = radii[index-step]
+			p2 = path[index]
+			index += step
+			l2_v = Sub_vec2(p2, p1)
+			l2_npv = segment_normal(p1, p2)
+			out_points = thicken_join(out_points, p1, l1_v, l1_npv, l2_npv, radius, joinstyle, resolution, mitre_limit)
+		}
+		if step < 0 {
+			break
+		}
+		step = -step
+		index += step
+	}
+	return out_points
+}
+
+//join two segments at p1, the outline side is the one l1_npv points to
+func thicken_join(out_points Vec2s, p1, l1_v, l1_npv, l2_npv Vec2, radius float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	d := Dot_vec2(l1_npv, l2_npv)
+	if d > 0.99999 {
+		//collinear, a single point will do
+		return append(out_points, p1, Add_vec2(p1, Scale_vec2(l1_npv, radius)))
+	}
+	var nbv Vec2
+	var c float32
+	if d < -0.99999 {
+		//reversal, the outside of the turn is straight on
+		nbv = Norm_vec2(l1_v)
+		c = 1.0
+	} else {
+		nbv = Norm_vec2(Add_vec2(l1_npv, l2_npv))
+		c = Dot_vec2(nbv, Norm_vec2(l1_v))
+	}
+	s := Dot_vec2(nbv, l1_npv)
+	mitre := (s > 0.0) && (1.0/s <= mitre_limit)
+	switch {
+	case mitre && ((c <= 0) || (joinstyle == 0)):
+		//mitre join
+		out_points = append(out_points, p1, Add_vec2(p1, Scale_vec2(nbv, radius/s)))
+	case (c <= 0) || (joinstyle <= 1):
+		//bevel join
+		out_points = append(out_points, p1, Add_vec2(p1, Scale_vec2(l1_npv, radius)))
+		out_points = append(out_points, p1, Add_vec2(p1, Scale_vec2(l2_npv, radius)))
+	default:
+		//round join
+		theta := float32(math.Acos(math.Max(-1.0, math.Min(1.0, float64(d)))))
+		out_points = thicken_arc(out_points, p1, Scale_vec2(l1_npv, radius), theta, int((theta/float32(math.Pi))*float32(resolution))+1)
+	}
+	return out_points
+}
+
+//sweep the radius vector rv around p counter clockwise by theta
+func thicken_arc(out_points Vec2s, p, rv Vec2, theta float32, segs int) Vec2s {
+	for i := 0; i <= segs; i++ {
+		angle := float64((float32(i) * theta) / float32(segs))
+		s := float32(math.Sin(angle))
+		c := float32(math.Cos(angle))
+		out_points = append(out_points, p, Add_vec2(p, Vec2{rv.X*c - rv.Y*s, rv.X*s + rv.Y*c}))
+	}
+	return out_points
+}
+
+//contact between thick lines in float64, shared by the float32 and float64 apis
+func contact64(a1, a2, b1, b2 point64, fr float64) *Contact {
+	c1, c2 := closest64(a1, a2, b1, b2)
+	dx, dy := c1.x-c2.x, c1.y-c2.y
+	d := math.Sqrt(dx*dx + dy*dy)
+	c := &Contact{}
+	c.P1 = &Point{float32(c1.x), float32(c1.y)}
+	c.P2 = &Point{float32(c2.x), float32(c2.y)}
+	c.Distance = float32(d - fr)
+	c.Depth = float32(math.Max(0.0, fr-d))
+	if d >= fr {
+		c.Mtv = &Point{0.0, 0.0}
+		return c
+	}
+	if d > 0.0 {
+		s := (fr - d) / d
+		c.Mtv = &Point{float32(dx * s), float32(dy * s)}
+		return c
+	}
+	//centre lines touch, so separate along the best edge normal of their minkowski difference
+	best, bx, by := math.Inf(1), 0.0, 0.0
+	for _, v := range []point64{{a2.x - a1.x, a2.y - a1.y}, {b2.x - b1.x, b2.y - b1.y}} {
+		l := math.Sqrt(v.x*v.x + v.y*v.y)
+		if l == 0.0 {
+			continue
+		}
+		nx, ny := v.y/l, -v.x/l
+		pa1, pa2 := a1.x*nx+a1.y*ny, a2.x*nx+a2.y*ny
+		pb1, pb2 := b1.x*nx+b1.y*ny, b2.x*nx+b2.y*ny
+		if m := math.Max(pb1, pb2) - math.Min(pa1, pa2) + fr; m < best {
+			best, bx, by = m, nx, ny
+		}
+		if m := math.Max(pa1, pa2) - math.Min(pb1, pb2) + fr; m < best {
+			best, bx, by = m, -nx, -ny
+		}
+	}
+	if math.IsInf(best, 1) {
+		best, bx, by = fr, 1.0, 0.0
+	}
+	c.Mtv = &Point{float32(bx * best), float32(by * best)}
+	return c
+}
+
+//collide tapered lines in float64, the gap between the outlines is convex along
+//the first line so a golden section search finds its smallest value
+func tapered64(a1, a2 point64, ar1, ar2 float64, b1, b2 point64, br1, br2 float64) bool {
+	if count, _, _ := intersect64(a1, a2, b1, b2); count != 0 {
+		return true
+	}
+	//gap between the outlines at s along the first line, convex in s
+	gap := func(s float64) float64 {
+		p := point64{a1.x + (a2.x-a1.x)*s, a1.y + (a2.y-a1.y)*s}
+		return taper_distance64(p, b1, b2, br1, br2) - (ar1 + (ar2-ar1)*s)
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 48; i++ {
+		m1 := lo + (hi-lo)*0.381966
+		m2 := hi - (hi-lo)*0.381966
+		if gap(m1) <= gap(m2) {
+			hi = m2
+		} else {
+			lo = m1
+		}
+	}
+	return math.Min(math.Min(gap(0.0), gap(1.0)), gap((lo+hi)*0.5)) <= 0.0
+}
+
+//distance from p to the outline of a tapered line, solved in closed form
+//as the radius gradient fixes the angle at which the closest point is seen
+func taper_distance64(p, b1, b2 point64, r1, r2 float64) float64 {
+	dx, dy := b2.x-b1.x, b2.y-b1.y
+	l := math.Sqrt(dx*dx + dy*dy)
+	t := 0.0
+	if l > 0.0 {
+		t0 := ((p.x-b1.x)*dx + (p.y-b1.y)*dy) / (l * l)
+		h := math.Abs((p.x-b1.x)*dy-(p.y-b1.y)*dx) / l
+		k := r2 - r1
+		if math.Abs(k) >= l {
+			//one end disc contains the other
+			if k > 0.0 {
+				t = 1.0
+			}
+		} else {
+			t = math.Max(0.0, math.Min(1.0, t0+k*h/(l*math.Sqrt(l*l-k*k))))
+		}
+	}
+	x, y := b1.x+dx*t-p.x, b1.y+dy*t-p.y
+	return math.Sqrt(x*x+y*y) - (r1 + (r2-r1)*t)
+}
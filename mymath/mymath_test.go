@@ -0,0 +1,278 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+//furthest any of the points is from p
+func furthest_from(pointsp *Points, p *Point) float32 {
+	d := float32(0.0)
+	for _, pp := range *pointsp {
+		if l := Distance_2d(pp, p); l > d {
+			d = l
+		}
+	}
+	return d
+}
+
+///////
+//tests
+///////
+
+//the original thickeners mitre every join, only the _ex versions bevel at a limit
+func TestThicken_mitre_limit(t *testing.T) {
+	//a 10 degree turn, its mitre reaches over 11 radii from the corner
+	path := &Points{&Point{0.0, 0.0}, &Point{100.0, 0.0}, &Point{1.5, 17.4}}
+	corner := (*path)[1]
+	tests := []struct {
+		name  string
+		strip *Points
+		min   float32
+		max   float32
+	}{
+		{"lines", Thicken_path_as_lines(path, 1.0, 0, 0, 16), 11.0, 12.0},
+		{"tristrip", Thicken_path_as_tristrip(path, 1.0, 0, 0, 16), 11.0, 12.0},
+		{"lines no limit", Thicken_path_as_lines_ex(path, 1.0, 0, 0, 16, No_mitre_limit), 11.0, 12.0},
+		{"lines limited", Thicken_path_as_lines_ex(path, 1.0, 0, 0, 16, Default_mitre_limit), 0.0, 1.01},
+		{"tristrip limited", Thicken_path_as_tristrip_ex(path, 1.0, 0, 0, 16, Default_mitre_limit), 0.0, 1.01},
+	}
+	for _, test := range tests {
+		//only points near the corner, the caps are far away
+		near := Points{}
+		for _, p := range *test.strip {
+			if Distance_2d(p, corner) < 50.0 {
+				near = append(near, p)
+			}
+		}
+		if d := furthest_from(&near, corner); (d < test.min) || (d > test.max) {
+			t.Errorf("%s: join reaches %v from the corner, want %v to %v", test.name, d, test.min, test.max)
+		}
+	}
+}
+
+func TestFit_radii(t *testing.T) {
+	tests := []struct {
+		radii []float32
+		n     int
+		want  []float32
+	}{
+		{[]float32{1.0, 2.0, 3.0}, 3, []float32{1.0, 2.0, 3.0}},
+		{[]float32{1.0, 2.0}, 4, []float32{1.0, 2.0, 2.0, 2.0}},
+		{[]float32{1.0, 2.0, 3.0}, 2, []float32{1.0, 2.0}},
+		{[]float32{}, 2, []float32{0.0, 0.0}},
+		{nil, 0, nil},
+	}
+	for _, test := range tests {
+		got := Fit_radii(test.radii, test.n)
+		if len(got) != len(test.want) {
+			t.Errorf("Fit_radii(%v, %d) = %v, want %v", test.radii, test.n, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Fit_radii(%v, %d) = %v, want %v", test.radii, test.n, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+//radii of the wrong length are fitted, not indexed past their end
+func TestThicken_variable_radii(t *testing.T) {
+	path := &Points{&Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{10.0, 10.0}, &Point{0.0, 10.0}}
+	fixed := Thicken_path_as_tristrip_ex(path, 2.0, 0, 0, 16, Default_mitre_limit)
+	for _, radii := range [][]float32{{2.0}, {2.0, 2.0}, {2.0, 2.0, 2.0, 2.0, 2.0, 2.0}} {
+		strip := Thicken_variable_path_as_tristrip(path, radii, 0, 0, 16, Default_mitre_limit)
+		if !points_near(fixed, Points_to_vec2s(strip), 0.0) {
+			t.Errorf("radii %v do not give the fixed radius strip", radii)
+		}
+		Thicken_variable_path_as_lines(path, radii, 3, 2, 16, Default_mitre_limit)
+		Thicken_variable_closed_path_as_tristrip(path, radii, 2, 16, Default_mitre_limit)
+		Thicken_variable_closed_path_as_lines(path, radii, 1, 16, Default_mitre_limit)
+	}
+}
+
+func TestCollide_tapered_lines(t *testing.T) {
+	//the first line widens from radius 1 to 3 along the x axis
+	a1, a2 := &Point{0.0, 0.0}, &Point{10.0, 0.0}
+	tests := []struct {
+		name   string
+		b1, b2 *Point
+		r1, r2 float32
+		want   bool
+	}{
+		{"parallel clear of the wide end", &Point{0.0, 4.5}, &Point{10.0, 4.5}, 1.0, 1.0, false},
+		{"parallel into the wide end", &Point{0.0, 3.5}, &Point{10.0, 3.5}, 1.0, 1.0, true},
+		{"parallel clear when reversed", &Point{0.0, 3.5}, &Point{10.0, 3.5}, 1.0, 0.0, false},
+		{"crossing", &Point{5.0, -5.0}, &Point{5.0, 5.0}, 0.0, 0.0, true},
+		{"disc just clear of the end", &Point{14.0, 0.0}, &Point{14.0, 0.0}, 0.9, 0.9, false},
+		{"disc just over the end", &Point{14.0, 0.0}, &Point{14.0, 0.0}, 1.1, 1.1, true},
+		{"disc beside the narrow end", &Point{0.0, 2.5}, &Point{0.0, 2.5}, 1.0, 1.0, false},
+		{"disc beside the wide end", &Point{10.0, 2.5}, &Point{10.0, 2.5}, 1.0, 1.0, true},
+	}
+	for _, test := range tests {
+		if got := Collide_tapered_lines_2d(a1, a2, 1.0, 3.0, test.b1, test.b2, test.r1, test.r2); got != test.want {
+			t.Errorf("%s: collide %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+//moving the first line by the mtv leaves the lines just touching
+func TestContact_thick_lines(t *testing.T) {
+	a1, a2 := &Point{0.0, 0.0}, &Point{10.0, 0.0}
+	tests := []struct {
+		name     string
+		b1, b2   *Point
+		r        float32
+		distance float32
+		depth    float32
+		mtv      *Point
+		after    float32
+	}{
+		{"apart", &Point{0.0, 5.0}, &Point{10.0, 5.0}, 2.0, 3.0, 0.0, &Point{0.0, 0.0}, 3.0},
+		{"touching", &Point{0.0, 2.0}, &Point{10.0, 2.0}, 2.0, 0.0, 0.0, &Point{0.0, 0.0}, 0.0},
+		{"overlapping above", &Point{2.0, 1.5}, &Point{8.0, 1.5}, 2.0, -0.5, 0.5, &Point{0.0, -0.5}, 0.0},
+		{"overlapping past the end", &Point{11.0, 0.0}, &Point{20.0, 0.0}, 2.0, -1.0, 1.0, &Point{-1.0, 0.0}, 0.0},
+		{"crossing", &Point{5.0, -1.0}, &Point{5.0, 4.0}, 1.0, -1.0, 1.0, &Point{0.0, -2.0}, 0.0},
+	}
+	for _, test := range tests {
+		c := Contact_thick_lines_2d(a1, a2, test.b1, test.b2, test.r)
+		if (math.Abs(float64(c.Distance-test.distance)) > 0.0001) || (math.Abs(float64(c.Depth-test.depth)) > 0.0001) {
+			t.Errorf("%s: distance %v depth %v, want %v %v", test.name, c.Distance, c.Depth, test.distance, test.depth)
+		}
+		if Distance_2d(c.Mtv, test.mtv) > 0.0001 {
+			t.Errorf("%s: mtv %v, want %v", test.name, c.Mtv, test.mtv)
+		}
+		moved := Contact_thick_lines_2d(Add_2d(a1, c.Mtv), Add_2d(a2, c.Mtv), test.b1, test.b2, test.r)
+		if math.Abs(float64(moved.Distance-test.after)) > 0.0001 {
+			t.Errorf("%s: distance %v after the mtv, want %v", test.name, moved.Distance, test.after)
+		}
+	}
+}
+
+//a closed path is stroked all the way round, an open one stops at its ends
+func TestThicken_closed_path(t *testing.T) {
+	square := test_square(0.0, 0.0, 10.0)
+	strip_area := func(stripp *Points) float32 {
+		strip := *stripp
+		area := float32(0.0)
+		for i := 2; i < len(strip); i++ {
+			tri := Points{strip[i-2], strip[i-1], strip[i]}
+			area += float32(math.Abs(float64(Polygon_area(&tri))))
+		}
+		return area
+	}
+	round := float32(76.0 + math.Pi)
+	tests := []struct {
+		name      string
+		joinstyle int
+		closed    float32
+		open      float32
+	}{
+		{"mitre", 0, 80.0, 60.0},
+		{"bevel", 1, 78.0, 59.0},
+		{"round", 2, round, 58.0 + float32(math.Pi)*0.5},
+	}
+	for _, test := range tests {
+		closed := Thicken_closed_path_as_tristrip(square, 1.0, test.joinstyle, 64, Default_mitre_limit)
+		open := Thicken_path_as_tristrip_ex(square, 1.0, 0, test.joinstyle, 64, Default_mitre_limit)
+		if a := strip_area(closed); math.Abs(float64(a-test.closed)) > 0.01 {
+			t.Errorf("%s: closed strip area %v, want %v", test.name, a, test.closed)
+		}
+		if a := strip_area(open); math.Abs(float64(a-test.open)) > 0.01 {
+			t.Errorf("%s: open strip area %v, want %v", test.name, a, test.open)
+		}
+		//a repeated first point at the end changes nothing
+		again := append(append(Points{}, *square...), (*square)[0])
+		if !points_near(closed, Points_to_vec2s(Thicken_closed_path_as_tristrip(&again, 1.0, test.joinstyle, 64, Default_mitre_limit)), 0.0) {
+			t.Errorf("%s: repeated first point changes the strip", test.name)
+		}
+	}
+}
+
+func TestDash_path(t *testing.T) {
+	path := &Points{&Point{0.0, 0.0}, &Point{100.0, 0.0}, &Point{100.0, 100.0}}
+	nan := float32(math.NaN())
+	tests := []struct {
+		name    string
+		pattern []float32
+		phase   float32
+		count   int
+		length  float32
+		first   *Point
+	}{
+		{"dashes", []float32{10.0, 5.0}, 0.0, 14, 135.0, &Point{0.0, 0.0}},
+		{"odd pattern", []float32{10.0}, 0.0, 10, 100.0, &Point{0.0, 0.0}},
+		{"phase into a gap", []float32{10.0, 5.0}, 12.0, 14, 132.0, &Point{3.0, 0.0}},
+		{"negative phase", []float32{10.0, 5.0}, -3.0, 14, 132.0, &Point{3.0, 0.0}},
+		{"dots", []float32{0.0, 10.0}, 0.0, 21, 0.0, &Point{0.0, 0.0}},
+		{"no gaps", []float32{10.0, 0.0}, 0.0, 20, 200.0, &Point{0.0, 0.0}},
+		{"all zero", []float32{0.0, 0.0}, 0.0, 1, 200.0, &Point{0.0, 0.0}},
+		{"negative", []float32{10.0, -5.0}, 0.0, 1, 200.0, &Point{0.0, 0.0}},
+		{"not a number", []float32{10.0, nan}, 0.0, 1, 200.0, &Point{0.0, 0.0}},
+	}
+	for _, test := range tests {
+		dashes := Dash_path(path, test.pattern, test.phase)
+		if len(dashes) != test.count {
+			t.Errorf("%s: %d dashes, want %d", test.name, len(dashes), test.count)
+			continue
+		}
+		length := float32(0.0)
+		for _, dash := range dashes {
+			if len(*dash) < 2 {
+				t.Errorf("%s: dash of %d points", test.name, len(*dash))
+			}
+			length += Path_length(dash)
+		}
+		if math.Abs(float64(length-test.length)) > 0.001 {
+			t.Errorf("%s: dashes %v long, want %v", test.name, length, test.length)
+		}
+		if Distance_2d((*dashes[0])[0], test.first) > 0.001 {
+			t.Errorf("%s: first dash at %v, want %v", test.name, (*dashes[0])[0], test.first)
+		}
+	}
+}
+
+//a lone point, as a zero length dash is, gets both caps round it
+func TestThicken_point(t *testing.T) {
+	p := &Point{5.0, 5.0}
+	dot := &Points{p, p}
+	strip_area := func(stripp *Points) float32 {
+		strip := *stripp
+		area := float32(0.0)
+		for i := 2; i < len(strip); i++ {
+			tri := Points{strip[i-2], strip[i-1], strip[i]}
+			area += float32(math.Abs(float64(Polygon_area(&tri))))
+		}
+		return area
+	}
+	tests := []struct {
+		name     string
+		capstyle int
+		area     float32
+	}{
+		{"butt", 0, 0.0},
+		{"square", 1, 16.0},
+		{"triangle", 2, 8.0},
+		{"round", 3, float32(4.0 * math.Pi)},
+	}
+	for _, test := range tests {
+		strip := Thicken_path_as_tristrip(dot, 2.0, test.capstyle, 0, 64)
+		if a := strip_area(strip); math.Abs(float64(a-test.area)) > 0.01 {
+			t.Errorf("%s: strip area %v, want %v", test.name, a, test.area)
+		}
+		outline := Thicken_path_as_lines(dot, 2.0, test.capstyle, 0, 64)
+		if a := Polygon_area(outline); math.Abs(float64(a-test.area)) > 0.01 {
+			t.Errorf("%s: outline area %v, want %v", test.name, a, test.area)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+//////////////////
+//public functions
+//////////////////
+
+//offset closed contours by distance, outwards when positive and inwards when negative.
+//outlines must wind counter clockwise and holes clockwise, as Clip_polygons returns them,
+//but a lone contour is taken as an outline whichever way it winds.
+//joinstyle: 0 mitre, 1 bevel, 2 round, 3 square, a mitre longer than mitre_limit times
+//the distance is bevelled. self intersections are removed so there may be several
+//result contours, closed, outlines counter clockwise and holes clockwise
+func Offset_polygons(contours []*Points, distance float32, joinstyle, resolution int, mitre_limit float32) []*Points {
+	raw := []*Points{}
+	for _, contour := range contours {
+		poly := tri_contour(contour)
+		if len(poly) < 3 {
+			continue
+		}
+		if (len(contours) == 1) && (tri_area(poly) < 0.0) {
+			for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+				poly[i], poly[j] = poly[j], poly[i]
+			}
+		}
+		raw = append(raw, offset_points(offset_contour(poly, float64(distance), joinstyle, -1, resolution, float64(mitre_limit), false)))
+	}
+	return Clip_polygons(raw, nil, 0, 2)
+}
+
+//closed outline at distance either side of an open path, capstyle as for Thicken_path_as_lines
+//with 0 butt, 1 square, 2 triangle and 3 round, joinstyle as for Offset_polygons.
+//self intersections are removed, as for Offset_polygons
+func Offset_polyline(pathp *Points, distance float32, capstyle, joinstyle, resolution int, mitre_limit float32) []*Points {
+	path := tri_polygon{}
+	for _, pp := range *pathp {
+		p := *pp
+		tp := point64{float64(p[0]), float64(p[1])}
+		if (len(path) == 0) || (path[len(path)-1] != tp) {
+			path = append(path, tp)
+		}
+	}
+	if len(path) < 2 {
+		return []*Points{}
+	}
+	//out along one side and back along the other, the ends are turned with caps
+	for i := len(path) - 2; i > 0; i-- {
+		path = append(path, path[i])
+	}
+	d := math.Abs(float64(distance))
+	raw := offset_points(offset_contour(path, d, joinstyle, capstyle, resolution, float64(mitre_limit), true))
+	return Clip_polygons([]*Points{raw}, nil, 0, 2)
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func offset_points(poly []point64) *Points {
+	out_points := make(Points, len(poly), len(poly))
+	for i, p := range poly {
+		out_points[i] = &Point{float32(p.x), float32(p.y)}
+	}
+	return &out_points
+}
+
+//offset every edge along its right hand normal, which points out of a counter clockwise
+//outline, and join the edges at each vertex. inside turns go back through the vertex so
+//the overlap they leave winds the other way and is dropped by the positive fill rule.
+//for an open path doubled back on itself, vertex 0 and the turn at the far end get caps
+func offset_contour(poly []point64, d float64, joinstyle, capstyle, resolution int, mitre_limit float64, open bool) []point64 {
+	n := len(poly)
+	normals := make([]point64, n, n)
+	for i := range poly {
+		e := fit_norm(fit_sub(poly[(i+1)%n], poly[i]))
+		normals[i] = point64{e.y, -e.x}
+	}
+	out := []point64{}
+	for i, p := range poly {
+		n1, n2 := normals[(i+n-1)%n], normals[i]
+		e1, e2 := point64{-n1.y, n1.x}, point64{-n2.y, n2.x}
+		angle := math.Atan2(n1.x*n2.y-n1.y*n2.x, fit_dot(n1, n2))
+		reversal := math.Abs(angle) > math.Pi-1.0e-9
+		switch {
+		case math.Abs(angle) < 1.0e-9:
+			out = append(out, fit_add(p, fit_scale(n1, d)))
+		case !reversal && (angle*d < 0.0):
+			out = append(out, fit_add(p, fit_scale(n1, d)), p, fit_add(p, fit_scale(n2, d)))
+		case open && ((i == 0) || (i == n/2)):
+			switch capstyle {
+			case 0:
+				out = offset_join(out, p, n1, n2, e1, e2, angle, d, 1, resolution, mitre_limit)
+			case 1:
+				out = offset_join(out, p, n1, n2, e1, e2, angle, d, 3, resolution, mitre_limit)
+			case 2:
+				out = append(out, fit_add(p, fit_scale(n1, d)), fit_add(p, fit_scale(e1, d)), fit_add(p, fit_scale(n2, d)))
+			default:
+				out = offset_join(out, p, n1, n2, e1, e2, angle, d, 2, resolution, mitre_limit)
+			}
+		default:
+			out = offset_join(out, p, n1, n2, e1, e2, angle, d, joinstyle, resolution, mitre_limit)
+		}
+	}
+	return out
+}
+
+//join the offset edges round the outside of a turn, the normals and edge
+//directions are unit vectors and angle turns n1 onto n2
+func offset_join(out []point64, p, n1, n2, e1, e2 point64, angle, d float64, joinstyle, resolution int, mitre_limit float64) []point64 {
+	v1, v2 := fit_scale(n1, d), fit_scale(n2, d)
+	//unit vector out from the vertex through the middle of the join
+	b := fit_norm(fit_add(v1, v2))
+	if math.Abs(angle) > math.Pi-1.0e-9 {
+		//reversal, the outside is straight on so sweep round that way
+		b = e1
+		angle = math.Copysign(math.Pi, d)
+	}
+	switch joinstyle {
+	case 0:
+		s := fit_dot(b, fit_norm(v1))
+		if (s > 0.0) && (1.0/s <= mitre_limit) {
+			return append(out, fit_add(p, fit_scale(b, math.Abs(d)/s)))
+		}
+	case 2:
+		segs := int((math.Abs(angle)/math.Pi)*float64(resolution)) + 1
+		for i := 0; i <= segs; i++ {
+			a := angle * float64(i) / float64(segs)
+			s, c := math.Sin(a), math.Cos(a)
+			out = append(out, point64{p.x + v1.x*c - v1.y*s, p.y + v1.x*s + v1.y*c})
+		}
+		return out
+	case 3:
+		//cut square across the middle of the join at the offset distance
+		ad := math.Abs(d)
+		t1 := (ad - fit_dot(v1, b)) / fit_dot(e1, b)
+		t2 := (ad - fit_dot(v2, b)) / fit_dot(e2, b)
+		return append(out, fit_add(fit_add(p, v1), fit_scale(e1, t1)), fit_add(fit_add(p, v2), fit_scale(e2, t2)))
+	}
+	return append(out, fit_add(p, v1), fit_add(p, v2))
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////
+//tests
+///////
+
+func TestOffset_polygons(t *testing.T) {
+	square := test_square(0.0, 0.0, 10.0)
+	clockwise := &Points{&Point{0.0, 0.0}, &Point{0.0, 10.0}, &Point{10.0, 10.0}, &Point{10.0, 0.0}}
+	hole := &Points{&Point{3.0, 3.0}, &Point{3.0, 7.0}, &Point{7.0, 7.0}, &Point{7.0, 3.0}}
+	round := float32(140.0 + math.Pi)
+	tests := []struct {
+		name      string
+		contours  []*Points
+		distance  float32
+		joinstyle int
+		area      float32
+		tolerance float32
+		count     int
+	}{
+		{"mitre", []*Points{square}, 1.0, 0, 144.0, 0.001, 1},
+		{"bevel", []*Points{square}, 1.0, 1, 142.0, 0.001, 1},
+		{"round", []*Points{square}, 1.0, 2, round, 0.05, 1},
+		{"inwards", []*Points{square}, -1.0, 0, 64.0, 0.001, 1},
+		{"inwards to nothing", []*Points{square}, -6.0, 0, 0.0, 0.001, 0},
+		{"lone clockwise outwards", []*Points{clockwise}, 1.0, 0, 144.0, 0.001, 1},
+		{"lone clockwise inwards", []*Points{clockwise}, -1.0, 0, 64.0, 0.001, 1},
+		{"with a hole", []*Points{square, hole}, 1.0, 0, 140.0, 0.001, 2},
+		{"hole closing up", []*Points{square, hole}, 2.5, 0, 225.0, 0.001, 1},
+	}
+	for _, test := range tests {
+		result := Offset_polygons(test.contours, test.distance, test.joinstyle, 32, Default_mitre_limit)
+		if len(result) != test.count {
+			t.Errorf("%s: %d contours, want %d", test.name, len(result), test.count)
+		}
+		if area := total_area(result); math.Abs(float64(area-test.area)) > float64(test.tolerance) {
+			t.Errorf("%s: area %v, want %v", test.name, area, test.area)
+		}
+	}
+}
+
+//a sharp point is mitred within the limit and bevelled past it
+func TestOffset_mitre_limit(t *testing.T) {
+	spike := &Points{&Point{0.0, 0.0}, &Point{100.0, 5.0}, &Point{0.0, 10.0}}
+	_, mitred := Path_bounds(Offset_polygons([]*Points{spike}, 1.0, 0, 0, 100.0)[0])
+	_, bevelled := Path_bounds(Offset_polygons([]*Points{spike}, 1.0, 0, 0, Default_mitre_limit)[0])
+	if (*mitred)[0] < 115.0 {
+		t.Errorf("mitred point reaches %v, want past 115", (*mitred)[0])
+	}
+	if (*bevelled)[0] > 101.01 {
+		t.Errorf("bevelled point reaches %v, want no further than 101", (*bevelled)[0])
+	}
+}
+
+func TestOffset_polyline(t *testing.T) {
+	line := &Points{&Point{0.0, 0.0}, &Point{10.0, 0.0}}
+	bend := &Points{&Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{10.0, 10.0}}
+	tests := []struct {
+		name      string
+		path      *Points
+		capstyle  int
+		joinstyle int
+		area      float32
+		tolerance float32
+	}{
+		{"butt", line, 0, 0, 20.0, 0.001},
+		{"square", line, 1, 0, 24.0, 0.001},
+		{"triangle", line, 2, 0, 22.0, 0.001},
+		{"round", line, 3, 0, float32(20.0 + math.Pi), 0.05},
+		{"mitred bend", bend, 0, 0, 40.0, 0.001},
+		{"bevelled bend", bend, 0, 1, 39.5, 0.001},
+	}
+	for _, test := range tests {
+		result := Offset_polyline(test.path, 1.0, test.capstyle, test.joinstyle, 32, Default_mitre_limit)
+		if len(result) != 1 {
+			t.Errorf("%s: %d contours, want 1", test.name, len(result))
+		}
+		if area := total_area(result); math.Abs(float64(area-test.area)) > float64(test.tolerance) {
+			t.Errorf("%s: area %v, want %v", test.name, area, test.area)
+		}
+	}
+}
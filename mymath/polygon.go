@@ -0,0 +1,165 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+//////////////////
+//public functions
+//////////////////
+
+//polygons are closed implicitly, a repeated first point at the end is ignored
+
+//signed area, positive when the polygon winds counter clockwise as the
+//outlines from Clip_polygons do, negative when it winds clockwise
+func Polygon_area(pathp *Points) float32 {
+	return float32(tri_area(tri_contour(pathp)))
+}
+
+//1 for counter clockwise, -1 for clockwise and 0 for no area
+func Polygon_winding(pathp *Points) int {
+	area := tri_area(tri_contour(pathp))
+	switch {
+	case area > 0.0:
+		return 1
+	case area < 0.0:
+		return -1
+	}
+	return 0
+}
+
+//centre of area, the mean of the points if the polygon has no area
+func Polygon_centroid(pathp *Points) *Point {
+	poly := tri_contour(pathp)
+	if len(poly) == 0 {
+		return &Point{0.0, 0.0}
+	}
+	//relative to the first point to keep precision far from the origin
+	o := poly[0]
+	area, cx, cy := 0.0, 0.0, 0.0
+	j := len(poly) - 1
+	for i := 0; i < len(poly); i++ {
+		x1, y1 := poly[j].x-o.x, poly[j].y-o.y
+		x2, y2 := poly[i].x-o.x, poly[i].y-o.y
+		a := x1*y2 - x2*y1
+		area += a
+		cx += (x1 + x2) * a
+		cy += (y1 + y2) * a
+		j = i
+	}
+	if area == 0.0 {
+		for _, p := range poly {
+			cx += p.x - o.x
+			cy += p.y - o.y
+		}
+		n := float64(len(poly))
+		return &Point{float32(o.x + cx/n), float32(o.y + cy/n)}
+	}
+	return &Point{float32(o.x + cx/(3.0*area)), float32(o.y + cy/(3.0*area))}
+}
+
+//length of the outline including the closing edge
+func Polygon_perimeter(pathp *Points) float32 {
+	poly := tri_contour(pathp)
+	length := 0.0
+	j := len(poly) - 1
+	for i := 0; i < len(poly); i++ {
+		length += math.Hypot(poly[i].x-poly[j].x, poly[i].y-poly[j].y)
+		j = i
+	}
+	return float32(length)
+}
+
+//axis aligned bounds of a path, min and max corners
+func Path_bounds(pathp *Points) (*Point, *Point) {
+	path := *pathp
+	if len(path) == 0 {
+		return &Point{0.0, 0.0}, &Point{0.0, 0.0}
+	}
+	minx, miny := (*path[0])[0], (*path[0])[1]
+	maxx, maxy := minx, miny
+	for _, pp := range path[1:] {
+		p := *pp
+		if p[0] < minx {
+			minx = p[0]
+		}
+		if p[1] < miny {
+			miny = p[1]
+		}
+		if p[0] > maxx {
+			maxx = p[0]
+		}
+		if p[1] > maxy {
+			maxy = p[1]
+		}
+	}
+	return &Point{minx, miny}, &Point{maxx, maxy}
+}
+
+//point in polygon, fill_rule as for Clip_polygons.
+//points on the outline are inside
+func Point_in_polygon(pp *Point, pathp *Points, fill_rule int) bool {
+	p := *pp
+	winding, boundary := polygon_winding_number(point64{float64(p[0]), float64(p[1])}, tri_contour(pathp))
+	return boundary || clip_inside(winding, fill_rule)
+}
+
+//true if the polygon is convex, collinear points are allowed but
+//a polygon that turns round more than once is not convex
+func Polygon_is_convex(pathp *Points) bool {
+	poly := tri_contour(pathp)
+	if len(poly) < 3 {
+		return false
+	}
+	sign := 0
+	turning := 0.0
+	for i := range poly {
+		p1, p2, p3 := poly[i], poly[(i+1)%len(poly)], poly[(i+2)%len(poly)]
+		if o := orient64(p1, p2, p3); o != 0 {
+			if (sign != 0) && (o != sign) {
+				return false
+			}
+			sign = o
+		}
+		a1 := math.Atan2(p2.y-p1.y, p2.x-p1.x)
+		a2 := math.Atan2(p3.y-p2.y, p3.x-p2.x)
+		turn := a2 - a1
+		if turn > math.Pi {
+			turn -= 2.0 * math.Pi
+		} else if turn < -math.Pi {
+			turn += 2.0 * math.Pi
+		}
+		turning += turn
+	}
+	return (sign != 0) && (math.Abs(math.Abs(turning)-2.0*math.Pi) < 1.0e-6)
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//winding number of a point, and whether it lies on the outline
+func polygon_winding_number(p point64, poly tri_polygon) (int, bool) {
+	winding := 0
+	j := len(poly) - 1
+	for i := 0; i < len(poly); i++ {
+		p1, p2 := poly[j], poly[i]
+		j = i
+		o := orient64(p1, p2, p)
+		if (o == 0) && on_segment64(p1, p2, p) {
+			return 0, true
+		}
+		if p1.y <= p.y {
+			if (p2.y > p.y) && (o > 0) {
+				winding++
+			}
+		} else if (p2.y <= p.y) && (o < 0) {
+			winding--
+		}
+	}
+	return winding, false
+}
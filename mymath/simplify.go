@@ -0,0 +1,133 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"container/heap"
+	"math"
+)
+
+//////////////////////////
+//private structures/types
+//////////////////////////
+
+type vw_point struct {
+	index int
+	area  float32
+	prev  *vw_point
+	next  *vw_point
+	slot  int
+}
+
+type vw_heap []*vw_point
+
+func (h vw_heap) Len() int           { return len(h) }
+func (h vw_heap) Less(i, j int) bool { return h[i].area < h[j].area }
+func (h vw_heap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].slot = i
+	h[j].slot = j
+}
+func (h *vw_heap) Push(x interface{}) {
+	p := x.(*vw_point)
+	p.slot = len(*h)
+	*h = append(*h, p)
+}
+func (h *vw_heap) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+//////////////////
+//public functions
+//////////////////
+
+//ramer douglas peucker simplification, no dropped point is further than
+//tolerance from the result. the kept points are the original points
+func Simplify_path_rdp(pathp *Points, tolerance float32) *Points {
+	path := *pathp
+	if len(path) < 3 {
+		return pathp
+	}
+	keep := make([]bool, len(path), len(path))
+	keep[0], keep[len(path)-1] = true, true
+	stack := [][2]int{{0, len(path) - 1}}
+	for len(stack) != 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := span[0], span[1]
+		index := -1
+		dist := tolerance
+		for i := first + 1; i < last; i++ {
+			if d := Distance_to_line_2d(path[i], path[first], path[last]); d > dist {
+				index, dist = i, d
+			}
+		}
+		if index != -1 {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+	out_points := Points{}
+	for i, p := range path {
+		if keep[i] {
+			out_points = append(out_points, p)
+		}
+	}
+	return &out_points
+}
+
+//visvalingam whyatt simplification, points are dropped smallest first while the
+//triangle they make with their neighbours has less than tolerance area.
+//the kept points are the original points
+func Simplify_path_vw(pathp *Points, tolerance float32) *Points {
+	path := *pathp
+	if len(path) < 3 {
+		return pathp
+	}
+	points := make([]*vw_point, len(path), len(path))
+	for i := range path {
+		points[i] = &vw_point{index: i}
+		if i != 0 {
+			points[i].prev = points[i-1]
+			points[i-1].next = points[i]
+		}
+	}
+	h := vw_heap{}
+	for _, p := range points[1 : len(points)-1] {
+		p.area = vw_area(path, p)
+		heap.Push(&h, p)
+	}
+	for h.Len() != 0 {
+		p := heap.Pop(&h).(*vw_point)
+		if p.area >= tolerance {
+			break
+		}
+		p.prev.next, p.next.prev = p.next, p.prev
+		//neighbours never drop below the area already removed
+		for _, n := range []*vw_point{p.prev, p.next} {
+			if (n.prev != nil) && (n.next != nil) {
+				n.area = float32(math.Max(float64(vw_area(path, n)), float64(p.area)))
+				heap.Fix(&h, n.slot)
+			}
+		}
+	}
+	out_points := Points{}
+	for p := points[0]; p != nil; p = p.next {
+		out_points = append(out_points, path[p.index])
+	}
+	return &out_points
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func vw_area(path Points, p *vw_point) float32 {
+	a, b, c := *path[p.prev.index], *path[p.index], *path[p.next.index]
+	return float32(math.Abs(float64((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])))) * 0.5
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+//the points of sub appear in path, in order, ends included
+func points_subset(sub, path *Points) bool {
+	s, p := *sub, *path
+	if (len(s) < 2) || (s[0] != p[0]) || (s[len(s)-1] != p[len(p)-1]) {
+		return false
+	}
+	j := 0
+	for _, pp := range p {
+		if (j < len(s)) && (s[j] == pp) {
+			j++
+		}
+	}
+	return j == len(s)
+}
+
+///////
+//tests
+///////
+
+func TestSimplify_path_rdp(t *testing.T) {
+	path := test_path(200)
+	last := len(*path)
+	for _, tolerance := range []float32{0.1, 1.0, 10.0} {
+		simple := Simplify_path_rdp(path, tolerance)
+		if !points_subset(simple, path) {
+			t.Fatalf("tolerance %v: not a subset of the path", tolerance)
+		}
+		if len(*simple) > last {
+			t.Errorf("tolerance %v: %d points, more than %d at a lower tolerance", tolerance, len(*simple), last)
+		}
+		last = len(*simple)
+		for _, p := range *path {
+			if d := distance_to_path(p, simple); d > tolerance {
+				t.Errorf("tolerance %v: %v dropped %v away", tolerance, p, d)
+				break
+			}
+		}
+	}
+	if last >= len(*path) {
+		t.Error("nothing dropped")
+	}
+}
+
+func TestSimplify_path_vw(t *testing.T) {
+	path := test_path(200)
+	last := len(*path)
+	for _, tolerance := range []float32{0.1, 1.0, 10.0, 100.0} {
+		simple := Simplify_path_vw(path, tolerance)
+		if !points_subset(simple, path) {
+			t.Fatalf("tolerance %v: not a subset of the path", tolerance)
+		}
+		if len(*simple) > last {
+			t.Errorf("tolerance %v: %d points, more than %d at a lower tolerance", tolerance, len(*simple), last)
+		}
+		last = len(*simple)
+		//every triangle left is at least the tolerance
+		s := *simple
+		for i := 2; i < len(s); i++ {
+			tri := Points{s[i-2], s[i-1], s[i]}
+			if a := Polygon_area(&tri); (a < tolerance) && (-a < tolerance) {
+				t.Errorf("tolerance %v: kept %v with area %v", tolerance, s[i-1], a)
+				break
+			}
+		}
+	}
+}
+
+//straight runs go down to their ends, short paths are left as they are
+func TestSimplify_straight(t *testing.T) {
+	line := &Points{&Point{0.0, 0.0}, &Point{1.0, 0.0}, &Point{2.0, 0.0}, &Point{5.0, 0.0}, &Point{9.0, 0.0}}
+	short := &Points{&Point{0.0, 0.0}, &Point{1.0, 5.0}}
+	for _, simplify := range []func(*Points, float32) *Points{Simplify_path_rdp, Simplify_path_vw} {
+		if s := simplify(line, 0.01); len(*s) != 2 {
+			t.Errorf("straight line simplified to %d points, want 2", len(*s))
+		}
+		if s := simplify(short, 100.0); len(*s) != 2 {
+			t.Errorf("two point path simplified to %d points", len(*s))
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"sort"
+)
+
+//////////////////////////
+//private structures/types
+//////////////////////////
+
+type point64 struct {
+	x float64
+	y float64
+}
+
+type tri_polygon []point64
+
+//////////////////
+//public functions
+//////////////////
+
+//triangulate a closed polygon with optional holes by ear clipping,
+//returns a list of triangles, three points per triangle
+func Triangulate_polygon(pathp *Points, holes []*Points) *Points {
+	poly := tri_contour(pathp)
+	if len(poly) < 3 {
+		return &Points{}
+	}
+	if tri_area(poly) < 0.0 {
+		tri_reverse(poly)
+	}
+	//holes wind the other way and are bridged into the outline right to left
+	hole_polys := []tri_polygon{}
+	for _, holep := range holes {
+		hole := tri_contour(holep)
+		if len(hole) < 3 {
+			continue
+		}
+		if tri_area(hole) > 0.0 {
+			tri_reverse(hole)
+		}
+		hole_polys = append(hole_polys, hole)
+	}
+	sort.Slice(hole_polys, func(i, j int) bool {
+		return hole_polys[i][tri_max_x(hole_polys[i])].x > hole_polys[j][tri_max_x(hole_polys[j])].x
+	})
+	for _, hole := range hole_polys {
+		poly = tri_bridge(poly, hole)
+	}
+	return tri_clip_ears(poly)
+}
+
+//convert a triangle list to a triangle strip by joining triangles with degenerates
+func Triangles_as_tristrip(trisp *Points) *Points {
+	tris := *trisp
+	out_points := Points{}
+	for i := 0; i+2 < len(tris); i += 3 {
+		if i != 0 {
+			out_points = append(out_points, out_points[len(out_points)-1])
+			out_points = append(out_points, tris[i])
+		}
+		out_points = append(out_points, tris[i], tris[i+1], tris[i+2])
+	}
+	return &out_points
+}
+
+///////////////////
+//private functions
+///////////////////
+
+func tri_contour(pathp *Points) tri_polygon {
+	poly := tri_polygon{}
+	for _, pp := range *pathp {
+		p := *pp
+		tp := point64{float64(p[0]), float64(p[1])}
+		if (len(poly) != 0) && (poly[len(poly)-1] == tp) {
+			continue
+		}
+		poly = append(poly, tp)
+	}
+	for (len(poly) > 1) && (poly[0] == poly[len(poly)-1]) {
+		poly = poly[:len(poly)-1]
+	}
+	return poly
+}
+
+func tri_area(poly tri_polygon) float64 {
+	area := 0.0
+	j := len(poly) - 1
+	for i := 0; i < len(poly); i++ {
+		area += poly[j].x*poly[i].y - poly[i].x*poly[j].y
+		j = i
+	}
+	return area * 0.5
+}
+
+func tri_reverse(poly tri_polygon) {
+	for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+		poly[i], poly[j] = poly[j], poly[i]
+	}
+}
+
+func tri_max_x(poly tri_polygon) int {
+	m := 0
+	for i := 1; i < len(poly); i++ {
+		if poly[i].x > poly[m].x {
+			m = i
+		}
+	}
+	return m
+}
+
+func tri_cross(p1, p2, p3 point64) float64 {
+	return (p2.x-p1.x)*(p3.y-p2.y) - (p2.y-p1.y)*(p3.x-p2.x)
+}
+
+func tri_inside(p, p1, p2, p3 point64) bool {
+	//inclusive point in ccw triangle test
+	return (tri_cross(p1, p2, p) >= 0.0) && (tri_cross(p2, p3, p) >= 0.0) && (tri_cross(p3, p1, p) >= 0.0)
+}
+
+//join a hole into the outline with a pair of coincident bridge edges
+func tri_bridge(poly, hole tri_polygon) tri_polygon {
+	mi := tri_max_x(hole)
+	m := hole[mi]
+	//cast a ray to the right and find the closest outline edge it hits
+	pi := -1
+	ix := math.Inf(1)
+	for i := 0; i < len(poly); i++ {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		if (a.y > m.y) == (b.y > m.y) {
+			if (a.y == m.y) && (a.x >= m.x) && (a.x < ix) {
+				ix, pi = a.x, i
+			}
+			continue
+		}
+		x := a.x + (m.y-a.y)*(b.x-a.x)/(b.y-a.y)
+		if (x < m.x) || (x >= ix) {
+			continue
+		}
+		ix = x
+		if a.x > b.x {
+			pi = i
+		} else {
+			pi = (i + 1) % len(poly)
+		}
+		if (x == a.x) && (m.y == a.y) {
+			pi = i
+		} else if (x == b.x) && (m.y == b.y) {
+			pi = (i + 1) % len(poly)
+		}
+	}
+	if pi == -1 {
+		return poly
+	}
+	//a reflex vertex inside the triangle m, i, p may block the view of p
+	ip := point64{ix, m.y}
+	p := poly[pi]
+	if p != ip {
+		t1, t2, t3 := m, ip, p
+		if tri_cross(t1, t2, t3) < 0.0 {
+			t2, t3 = t3, t2
+		}
+		best := math.Inf(-1)
+		best_dist := math.Inf(1)
+		for i := 0; i < len(poly); i++ {
+			v := poly[i]
+			if (v == p) || !tri_inside(v, t1, t2, t3) {
+				continue
+			}
+			prev, next := poly[(i+len(poly)-1)%len(poly)], poly[(i+1)%len(poly)]
+			if tri_cross(prev, v, next) > 0.0 {
+				continue
+			}
+			dx, dy := v.x-m.x, v.y-m.y
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d == 0.0 {
+				continue
+			}
+			c := dx / d
+			if (c > best) || ((c == best) && (d < best_dist)) {
+				best, best_dist, pi = c, d, i
+			}
+		}
+	}
+	out := make(tri_polygon, 0, len(poly)+len(hole)+2)
+	out = append(out, poly[:pi+1]...)
+	for i := 0; i <= len(hole); i++ {
+		out = append(out, hole[(mi+i)%len(hole)])
+	}
+	out = append(out, poly[pi:]...)
+	return out
+}
+
+func tri_clip_ears(poly tri_polygon) *Points {
+	n := len(poly)
+	next := make([]int, n, n)
+	prev := make([]int, n, n)
+	for i := 0; i < n; i++ {
+		next[i] = (i + 1) % n
+		prev[i] = (i + n - 1) % n
+	}
+	out_points := Points{}
+	i := 0
+	misses := 0
+	for n > 2 {
+		a, b, c := poly[prev[i]], poly[i], poly[next[i]]
+		cross := tri_cross(a, b, c)
+		is_ear := cross > 0.0
+		if is_ear {
+			//no reflex vertex may sit inside the ear
+			for j := next[next[i]]; j != prev[i]; j = next[j] {
+				v := poly[j]
+				if (v == a) || (v == b) || (v == c) {
+					continue
+				}
+				if tri_cross(poly[prev[j]], v, poly[next[j]]) > 0.0 {
+					continue
+				}
+				if tri_inside(v, a, b, c) {
+					is_ear = false
+					break
+				}
+			}
+		}
+		//collinear vertices are dropped, and if nothing is clippable we force progress
+		if is_ear || (cross == 0.0) || (misses > n) {
+			if cross > 0.0 {
+				out_points = append(out_points,
+					&Point{float32(a.x), float32(a.y)},
+					&Point{float32(b.x), float32(b.y)},
+					&Point{float32(c.x), float32(c.y)})
+			}
+			next[prev[i]] = next[i]
+			prev[next[i]] = prev[i]
+			i = prev[i]
+			n--
+			misses = 0
+			continue
+		}
+		i = next[i]
+		misses++
+	}
+	return &out_points
+}
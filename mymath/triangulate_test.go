@@ -0,0 +1,79 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////
+//tests
+///////
+
+//the triangles all wind counter clockwise, cover the polygon exactly and stay
+//out of the holes
+func TestTriangulate_polygon(t *testing.T) {
+	u_shape := &Points{&Point{0.0, 0.0}, &Point{30.0, 0.0}, &Point{30.0, 30.0}, &Point{20.0, 30.0},
+		&Point{20.0, 10.0}, &Point{10.0, 10.0}, &Point{10.0, 30.0}, &Point{0.0, 30.0}}
+	tests := []struct {
+		name  string
+		path  *Points
+		holes []*Points
+		area  float32
+		tris  int
+	}{
+		{"square", test_square(0.0, 0.0, 10.0), nil, 100.0, 2},
+		{"clockwise square", &Points{&Point{0.0, 0.0}, &Point{0.0, 10.0}, &Point{10.0, 10.0}, &Point{10.0, 0.0}}, nil, 100.0, 2},
+		{"square with a hole", test_square(0.0, 0.0, 10.0), []*Points{test_square(3.0, 3.0, 4.0)}, 84.0, 8},
+		{"square with two holes", test_square(0.0, 0.0, 10.0),
+			[]*Points{test_square(1.0, 1.0, 2.0), test_square(6.0, 6.0, 3.0)}, 87.0, 14},
+		{"u shape", u_shape, nil, 700.0, 6},
+		{"repeated first point", &Points{&Point{0.0, 0.0}, &Point{10.0, 0.0}, &Point{10.0, 10.0}, &Point{0.0, 0.0}}, nil, 50.0, 1},
+		{"too few points", &Points{&Point{0.0, 0.0}, &Point{10.0, 0.0}}, nil, 0.0, 0},
+	}
+	for _, test := range tests {
+		tris := *Triangulate_polygon(test.path, test.holes)
+		if len(tris) != test.tris*3 {
+			t.Errorf("%s: %d triangle points, want %d", test.name, len(tris), test.tris*3)
+			continue
+		}
+		area := float32(0.0)
+		for i := 0; i < len(tris); i += 3 {
+			tri := tris[i : i+3]
+			a := Polygon_area(&tri)
+			if a <= 0.0 {
+				t.Errorf("%s: triangle %v has area %v", test.name, tri, a)
+			}
+			area += a
+			c := Polygon_centroid(&tri)
+			if !Point_in_polygon(c, test.path, 1) {
+				t.Errorf("%s: triangle %v outside the polygon", test.name, tri)
+			}
+			for _, hole := range test.holes {
+				if Point_in_polygon(c, hole, 1) {
+					t.Errorf("%s: triangle %v inside a hole", test.name, tri)
+				}
+			}
+		}
+		if math.Abs(float64(area-test.area)) > 0.001 {
+			t.Errorf("%s: triangle areas sum to %v, want %v", test.name, area, test.area)
+		}
+	}
+}
+
+func TestTriangles_as_tristrip(t *testing.T) {
+	tris := Triangulate_polygon(test_square(0.0, 0.0, 10.0), []*Points{test_square(3.0, 3.0, 4.0)})
+	strip := *Triangles_as_tristrip(tris)
+	//every triangle of the list is in the strip, the rest have no area
+	area := float32(0.0)
+	for i := 2; i < len(strip); i++ {
+		tri := Points{strip[i-2], strip[i-1], strip[i]}
+		area += float32(math.Abs(float64(Polygon_area(&tri))))
+	}
+	if math.Abs(float64(area-84.0)) > 0.001 {
+		t.Errorf("strip triangle areas sum to %v, want 84", area)
+	}
+}
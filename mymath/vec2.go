@@ -0,0 +1,269 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+)
+
+/////////////////////////
+//public structures/types
+/////////////////////////
+
+//value type 2d point, no heap allocation per point or per operation
+type Vec2 struct {
+	X float32
+	Y float32
+}
+
+//flat path storage
+type Vec2s []Vec2
+
+//////////////////
+//public functions
+//////////////////
+
+//////////
+//adapters
+//////////
+
+func Point_to_vec2(pp *Point) Vec2 {
+	p := *pp
+	return Vec2{p[0], p[1]}
+}
+
+func Vec2_to_point(v Vec2) *Point {
+	return &Point{v.X, v.Y}
+}
+
+func Points_to_vec2s(pointsp *Points) Vec2s {
+	points := *pointsp
+	path := make(Vec2s, len(points), len(points))
+	for i, pp := range points {
+		p := *pp
+		path[i] = Vec2{p[0], p[1]}
+	}
+	return path
+}
+
+//points and their values share backing arrays, three allocations in all
+func Vec2s_to_points(path Vec2s) *Points {
+	values := make([]float32, len(path)*2, len(path)*2)
+	headers := make([]Point, len(path), len(path))
+	points := make(Points, len(path), len(path))
+	for i, v := range path {
+		values[i*2], values[i*2+1] = v.X, v.Y
+		headers[i] = values[i*2 : i*2+2 : i*2+2]
+		points[i] = &headers[i]
+	}
+	return &points
+}
+
+//////////////////
+//vector functions
+//////////////////
+
+func Equal_vec2(v1, v2 Vec2) bool {
+	return v1 == v2
+}
+
+func Add_vec2(v1, v2 Vec2) Vec2 {
+	return Vec2{v1.X + v2.X, v1.Y + v2.Y}
+}
+
+func Sub_vec2(v1, v2 Vec2) Vec2 {
+	return Vec2{v1.X - v2.X, v1.Y - v2.Y}
+}
+
+func Scale_vec2(v Vec2, s float32) Vec2 {
+	return Vec2{v.X * s, v.Y * s}
+}
+
+func Perp_vec2(v Vec2) Vec2 {
+	return Vec2{v.Y, -v.X}
+}
+
+func Dot_vec2(v1, v2 Vec2) float32 {
+	return v1.X*v2.X + v1.Y*v2.Y
+}
+
+func Length_vec2(v Vec2) float32 {
+	return float32(math.Sqrt(float64(Dot_vec2(v, v))))
+}
+
+func Norm_vec2(v Vec2) Vec2 {
+	l := Length_vec2(v)
+	if l == 0 {
+		return Vec2{0, 0}
+	}
+	return Vec2{v.X / l, v.Y / l}
+}
+
+func Distance_vec2(v1, v2 Vec2) float32 {
+	return Length_vec2(Sub_vec2(v2, v1))
+}
+
+func Distance_squared_vec2(v1, v2 Vec2) float32 {
+	v := Sub_vec2(v2, v1)
+	return Dot_vec2(v, v)
+}
+
+func Distance_to_line_vec2(v, v1, v2 Vec2) float32 {
+	return float32(math.Sqrt(float64(Distance_squared_to_line_vec2(v, v1, v2))))
+}
+
+func Distance_squared_to_line_vec2(v, v1, v2 Vec2) float32 {
+	lv := Sub_vec2(v2, v1)
+	pv := Sub_vec2(v, v1)
+	c1 := Dot_vec2(pv, lv)
+	if c1 <= 0 {
+		return Distance_squared_vec2(v, v1)
+	}
+	c2 := Dot_vec2(lv, lv)
+	if c2 <= c1 {
+		return Distance_squared_vec2(v, v2)
+	}
+	return Distance_squared_vec2(v, Add_vec2(v1, Scale_vec2(lv, c1/c2)))
+}
+
+/////////////////////
+//collision functions
+/////////////////////
+
+func Collide_lines_vec2(l1_p1, l1_p2, l2_p1, l2_p2 Vec2) bool {
+	ax := l1_p2.X - l1_p1.X
+	ay := l1_p2.Y - l1_p1.Y
+	bx := l2_p1.X - l2_p2.X
+	by := l2_p1.Y - l2_p2.Y
+	cx := l1_p1.X - l2_p1.X
+	cy := l1_p1.Y - l2_p1.Y
+	an := by*cx - bx*cy
+	ad := ay*bx - ax*by
+	bn := ax*cy - ay*cx
+	bd := ay*bx - ax*by
+	if (ad == 0) || (bd == 0) {
+		return false
+	}
+	if ad > 0 {
+		if (an < 0) || (an > ad) {
+			return false
+		}
+	} else {
+		if (an > 0) || (an < ad) {
+			return false
+		}
+	}
+	if bd > 0 {
+		if (bn < 0) || (bn > bd) {
+			return false
+		}
+	} else {
+		if (bn > 0) || (bn < bd) {
+			return false
+		}
+	}
+	return true
+}
+
+func Collide_thick_lines_vec2(tl1_p1, tl1_p2, tl2_p1, tl2_p2 Vec2, r float32) bool {
+	if Collide_lines_vec2(tl1_p1, tl1_p2, tl2_p1, tl2_p2) {
+		return true
+	}
+	r *= r
+	if Distance_squared_to_line_vec2(tl2_p1, tl1_p1, tl1_p2) <= r {
+		return true
+	}
+	if Distance_squared_to_line_vec2(tl2_p2, tl1_p1, tl1_p2) <= r {
+		return true
+	}
+	if Distance_squared_to_line_vec2(tl1_p1, tl2_p1, tl2_p2) <= r {
+		return true
+	}
+	if Distance_squared_to_line_vec2(tl1_p2, tl2_p1, tl2_p2) <= r {
+		return true
+	}
+	return false
+}
+
+//collide two thick lines whose radii vary linearly from one end to the other
+func Collide_tapered_lines_vec2(tl1_p1, tl1_p2 Vec2, tl1_r1, tl1_r2 float32, tl2_p1, tl2_p2 Vec2, tl2_r1, tl2_r2 float32) bool {
+	a1 := point64{float64(tl1_p1.X), float64(tl1_p1.Y)}
+	a2 := point64{float64(tl1_p2.X), float64(tl1_p2.Y)}
+	b1 := point64{float64(tl2_p1.X), float64(tl2_p1.Y)}
+	b2 := point64{float64(tl2_p2.X), float64(tl2_p2.Y)}
+	return tapered64(a1, a2, float64(tl1_r1), float64(tl1_r2), b1, b2, float64(tl2_r1), float64(tl2_r2))
+}
+
+////////////////
+//path functions
+////////////////
+
+func Bezier_path_as_lines_vec2(p1, p2, p3, p4 Vec2, distance_tolerance float32) Vec2s {
+	points := Vec2s{p1}
+	points = recursive_bezier(p1.X, p1.Y, p2.X, p2.Y, p3.X, p3.Y, p4.X, p4.Y, points, distance_tolerance)
+	return append(points, p4)
+}
+
+func Thicken_path_as_lines_vec2(path Vec2s, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return Thicken_variable_path_as_lines_vec2(path, path_radius(len(path), radius), capstyle, joinstyle, resolution, mitre_limit)
+}
+
+func Thicken_path_as_tristrip_vec2(path Vec2s, radius float32, capstyle, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return Thicken_variable_path_as_tristrip_vec2(path, path_radius(len(path), radius), capstyle, joinstyle, resolution, mitre_limit)
+}
+
+func Thicken_closed_path_as_lines_vec2(path Vec2s, radius float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return Thicken_variable_closed_path_as_lines_vec2(path, path_radius(len(path), radius), joinstyle, resolution, mitre_limit)
+}
+
+func Thicken_closed_path_as_tristrip_vec2(path Vec2s, radius float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return Thicken_variable_closed_path_as_tristrip_vec2(path, path_radius(len(path), radius), joinstyle, resolution, mitre_limit)
+}
+
+func Thicken_variable_path_as_lines_vec2(path Vec2s, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return loops_as_lines(thicken_loops(path, radii, capstyle, joinstyle, resolution, mitre_limit, false))
+}
+
+func Thicken_variable_path_as_tristrip_vec2(path Vec2s, radii []float32, capstyle, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return loops_as_tristrip(thicken_loops(path, radii, capstyle, joinstyle, resolution, mitre_limit, false))
+}
+
+func Thicken_variable_closed_path_as_lines_vec2(path Vec2s, radii []float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return loops_as_lines(thicken_loops(path, radii, 0, joinstyle, resolution, mitre_limit, true))
+}
+
+func Thicken_variable_closed_path_as_tristrip_vec2(path Vec2s, radii []float32, joinstyle, resolution int, mitre_limit float32) Vec2s {
+	return loops_as_tristrip(thicken_loops(path, radii, 0, joinstyle, resolution, mitre_limit, true))
+}
+
+///////////////////
+//private functions
+///////////////////
+
+//outline of each loop of centre and outline point pairs, each closed on itself
+func loops_as_lines(loops []Vec2s) Vec2s {
+	out_points := Vec2s{}
+	for _, pairs := range loops {
+		start := len(out_points)
+		for i := 1; i < len(pairs); i += 2 {
+			out_points = append(out_points, pairs[i])
+		}
+		out_points = append(out_points, out_points[start])
+	}
+	return out_points
+}
+
+func loops_as_tristrip(loops []Vec2s) Vec2s {
+	out_points := Vec2s{}
+	for _, pairs := range loops {
+		if len(out_points) != 0 {
+			//degenerate triangles bridge from one loop to the next
+			out_points = append(out_points, out_points[len(out_points)-1], pairs[0])
+		}
+		out_points = append(out_points, pairs...)
+		out_points = append(out_points, pairs[0], pairs[1])
+	}
+	return out_points
+}
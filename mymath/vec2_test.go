@@ -0,0 +1,170 @@
+// Copyright (C) 2014 Chris Hinsley.
+
+//package name
+package mymath
+
+//package imports
+import (
+	"math"
+	"testing"
+)
+
+///////////////////
+//private functions
+///////////////////
+
+//a wandering path of n points, as a routed board trace might be, every
+//join turns by between 7 and 79 degrees one way or the other
+func test_path(n int) *Points {
+	path := make(Points, n, n)
+	x, y, heading := float32(0.0), float32(0.0), 0.0
+	for i := range path {
+		path[i] = &Point{x, y}
+		heading += (float64((i*7)%11) - 5.5) * 0.25
+		step := float32(4 + i%3)
+		x += float32(math.Cos(heading)) * step
+		y += float32(math.Sin(heading)) * step
+	}
+	return &path
+}
+
+//points within tolerance of the vec2 path, the join maths is rearranged
+//so the last bits can differ
+func points_near(pointsp *Points, path Vec2s, tolerance float64) bool {
+	points := *pointsp
+	if len(points) != len(path) {
+		return false
+	}
+	for i, pp := range points {
+		p := *pp
+		if (math.Abs(float64(p[0]-path[i].X)) > tolerance) || (math.Abs(float64(p[1]-path[i].Y)) > tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+///////
+//tests
+///////
+
+func TestThicken_vec2(t *testing.T) {
+	pathp := test_path(100)
+	path := Points_to_vec2s(pathp)
+	for capstyle := 0; capstyle < 4; capstyle++ {
+		for joinstyle := 0; joinstyle < 3; joinstyle++ {
+			strip := pointer_thicken_path_as_tristrip(pathp, 5.0, capstyle, joinstyle, 16)
+			if !points_near(strip, Thicken_path_as_tristrip_vec2(path, 5.0, capstyle, joinstyle, 16, No_mitre_limit), 0.001) {
+				t.Errorf("tristrip cap %d join %d differs from the pointer version", capstyle, joinstyle)
+			}
+			lines := pointer_thicken_path_as_lines(pathp, 5.0, capstyle, joinstyle, 16)
+			if !points_near(lines, Thicken_path_as_lines_vec2(path, 5.0, capstyle, joinstyle, 16, No_mitre_limit), 0.001) {
+				t.Errorf("lines cap %d join %d differs from the pointer version", capstyle, joinstyle)
+			}
+		}
+	}
+}
+
+func TestBezier_vec2(t *testing.T) {
+	p1, p2, p3, p4 := &Point{0.0, 0.0}, &Point{0.0, 500.0}, &Point{500.0, 500.0}, &Point{500.0, 0.0}
+	curve := pointer_bezier_path_as_lines(p1, p2, p3, p4, 0.5)
+	if !points_near(curve, Bezier_path_as_lines_vec2(Point_to_vec2(p1), Point_to_vec2(p2), Point_to_vec2(p3), Point_to_vec2(p4), 0.5), 0.0) {
+		t.Error("bezier differs from the pointer version")
+	}
+}
+
+func TestCollide_vec2(t *testing.T) {
+	pathp := test_path(50)
+	path := *pathp
+	for i := 1; i < len(path); i++ {
+		for j := 1; j < len(path); j++ {
+			a1, a2 := path[i-1], Add_2d(path[j], &Point{0.0, 3.0})
+			b1, b2 := path[i], path[j-1]
+			want := pointer_collide_thick_lines_2d(a1, a2, b1, b2, 2.0)
+			got := Collide_thick_lines_vec2(Point_to_vec2(a1), Point_to_vec2(a2), Point_to_vec2(b1), Point_to_vec2(b2), 2.0)
+			if got != want {
+				t.Fatalf("segments %d %d collide %v, pointer version says %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+////////////
+//benchmarks
+////////////
+
+//each vec2 function is paired with the pointer implementation it replaced
+
+func BenchmarkThicken_path_as_tristrip_pointer(b *testing.B) {
+	pathp := test_path(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pointer_thicken_path_as_tristrip(pathp, 5.0, 3, 2, 16)
+	}
+}
+
+func BenchmarkThicken_path_as_tristrip_vec2(b *testing.B) {
+	path := Points_to_vec2s(test_path(1000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Thicken_path_as_tristrip_vec2(path, 5.0, 3, 2, 16, No_mitre_limit)
+	}
+}
+
+func BenchmarkThicken_path_as_lines_pointer(b *testing.B) {
+	pathp := test_path(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pointer_thicken_path_as_lines(pathp, 5.0, 3, 2, 16)
+	}
+}
+
+func BenchmarkThicken_path_as_lines_vec2(b *testing.B) {
+	path := Points_to_vec2s(test_path(1000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Thicken_path_as_lines_vec2(path, 5.0, 3, 2, 16, No_mitre_limit)
+	}
+}
+
+func BenchmarkBezier_path_as_lines_pointer(b *testing.B) {
+	p1, p2, p3, p4 := &Point{0.0, 0.0}, &Point{0.0, 500.0}, &Point{500.0, 500.0}, &Point{500.0, 0.0}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pointer_bezier_path_as_lines(p1, p2, p3, p4, 0.1)
+	}
+}
+
+func BenchmarkBezier_path_as_lines_vec2(b *testing.B) {
+	p1, p2, p3, p4 := Vec2{0.0, 0.0}, Vec2{0.0, 500.0}, Vec2{500.0, 500.0}, Vec2{500.0, 0.0}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Bezier_path_as_lines_vec2(p1, p2, p3, p4, 0.1)
+	}
+}
+
+func BenchmarkCollide_thick_lines_pointer(b *testing.B) {
+	path := *test_path(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % (len(path) - 3)
+		pointer_collide_thick_lines_2d(path[j], path[j+1], path[j+2], path[j+3], 2.0)
+	}
+}
+
+func BenchmarkCollide_thick_lines_vec2(b *testing.B) {
+	path := Points_to_vec2s(test_path(1000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % (len(path) - 3)
+		Collide_thick_lines_vec2(path[j], path[j+1], path[j+2], path[j+3], 2.0)
+	}
+}